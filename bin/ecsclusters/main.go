@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var region string
+
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Region: region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	// List every cluster in the region unless specific ones were asked for
+	var clusters []libecs.Cluster
+	if names := flag.Args(); len(names) > 0 {
+		clusters, err = ecs.DescribeClusters(names)
+	} else {
+		clusters, err = ecs.ListClusters()
+	}
+	if err != nil {
+		fmt.Printf("failed to list clusters: %s\n", err)
+		return 3
+	}
+
+	output := libecs.NewColorServiceOutputer(true)
+	if err := output.DisplayClusters(clusters, os.Stdout); err != nil {
+		fmt.Printf("failed to display: %s\n", err)
+		return 3
+	}
+
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func printEvents(events []libecs.ServiceEvent) {
+	for _, ev := range events {
+		fmt.Printf("%s    %s\n", ev.CreatedAt.Format("01-02-2006 15:04:05"), ev.Message)
+	}
+}
+
+func mainInner() int {
+	var clusterName, serviceName, region string
+	var since, interval time.Duration
+	var follow bool
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&serviceName, "service", "gregord", "service name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.DurationVar(&since, "since", time.Hour, "show events from this far back")
+	flag.BoolVar(&follow, "follow", false, "keep polling and print new events as they arrive")
+	flag.DurationVar(&interval, "interval", 10*time.Second, "polling interval for --follow")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// Several events can share a timestamp, so each poll includes the newest
+	// timestamp already printed and skips the events seen there by ID.
+	last := time.Now().Add(-since)
+	seen := make(map[string]bool)
+	for {
+		events, err := ecs.ServiceEventsWithContext(ctx, serviceName, last.Add(-time.Nanosecond))
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0
+			}
+			fmt.Printf("failed to get service events: %s\n", err)
+			return 3
+		}
+		var unseen []libecs.ServiceEvent
+		for _, ev := range events {
+			if !seen[ev.ID] {
+				unseen = append(unseen, ev)
+			}
+		}
+		printEvents(unseen)
+		if len(events) > 0 && events[len(events)-1].CreatedAt.After(last) {
+			last = events[len(events)-1].CreatedAt
+			seen = make(map[string]bool)
+		}
+		for _, ev := range events {
+			if ev.CreatedAt.Equal(last) {
+				seen[ev.ID] = true
+			}
+		}
+		if !follow {
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(interval):
+		}
+	}
+}
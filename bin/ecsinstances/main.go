@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, region string
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	instances, err := ecs.ListContainerInstances()
+	if err != nil {
+		fmt.Printf("failed to list container instances: %s\n", err)
+		return 3
+	}
+
+	output := libecs.NewColorServiceOutputer(true)
+	if err := output.DisplayInstances(instances, os.Stdout); err != nil {
+		fmt.Printf("failed to display: %s\n", err)
+		return 3
+	}
+
+	return 0
+}
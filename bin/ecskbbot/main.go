@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -32,6 +33,10 @@ type Options struct {
 	ShortArns       bool
 	KeybaseLocation string
 	Home            string
+	CommandTimeout  time.Duration
+	GraphRenderer   libecs.GraphRenderer
+	Alarms          bool
+	AlarmNamePrefix string
 }
 
 type BotServer struct {
@@ -71,10 +76,20 @@ List all running tasks and services on an ECS cluster`,
 							MobileBody:  listExtendedBody,
 						},
 					},
+					{
+						Name:        "ecsclusters",
+						Usage:       "",
+						Description: "List all ECS clusters in the region with task and service counts",
+					},
+					{
+						Name:        "ecsdiff",
+						Usage:       "<cluster> <service>",
+						Description: "Show what changed between a service's task definition and the previous revision, without environment values",
+					},
 					{
 						Name:        "ecssvcgraph",
-						Usage:       "<cluster> <service> <cpu|mem>",
-						Description: "Attach a graph of service perf",
+						Usage:       "<cluster> <service> <cpu|mem> [range]",
+						Description: "Attach a graph of service perf over the last 24h, or a range like 6h, 7d or 2024-05-01/2024-05-03",
 					},
 				},
 			},
@@ -82,18 +97,29 @@ List all running tasks and services on an ECS cluster`,
 	}
 }
 
-func (s *BotServer) runServiceOutput(cluster string, out io.Writer) error {
+// commandContext bounds the AWS work done on behalf of a single chat command.
+// A zero CommandTimeout means no deadline.
+func (s *BotServer) commandContext() (context.Context, context.CancelFunc) {
+	if s.opts.CommandTimeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.opts.CommandTimeout)
+}
+
+func (s *BotServer) runServiceOutput(ctx context.Context, cluster string, out io.Writer) error {
 
 	ecs, err := libecs.New(libecs.ECSConfig{
-		Cluster: cluster,
-		Region:  s.opts.Region,
+		Cluster:         cluster,
+		Region:          s.opts.Region,
+		Alarms:          s.opts.Alarms,
+		AlarmNamePrefix: s.opts.AlarmNamePrefix,
 	})
 	if err != nil {
 		s.debug("failed to create ECS API object: %s", err.Error())
 		return err
 	}
 
-	services, err := ecs.ListServices()
+	services, err := ecs.ListServicesWithContext(ctx)
 	if err != nil {
 		s.debug("failed to list services: %s", err.Error())
 		return err
@@ -116,11 +142,95 @@ func (s *BotServer) handleCommand(conv chat1.ConvSummary, msg chat1.MsgSummary)
 	switch {
 	case strings.HasPrefix(body, "!ecslist"):
 		s.handleListCommand(conv, msg)
+	case strings.HasPrefix(body, "!ecsclusters"):
+		s.handleClusters(conv, msg)
+	case strings.HasPrefix(body, "!ecsdiff"):
+		s.handleDiff(conv, msg)
 	case strings.HasPrefix(body, "!ecssvcgraph"):
 		s.handleGraph(conv, msg)
 	}
 }
 
+func (s *BotServer) handleDiff(conv chat1.ConvSummary, msg chat1.MsgSummary) (err error) {
+	defer func() {
+		if err != nil {
+			if _, err := s.kbc.ReactByConvID(conv.Id, msg.Id, ":-1:"); err != nil {
+				s.debug("failed to react: %s", err)
+			}
+			s.kbc.SendMessageByConvID(conv.Id, "invalid diff command: %s", err)
+		}
+	}()
+	toks := strings.Split(strings.Trim(msg.Content.Text.Body, " "), " ")
+	if len(toks) != 3 {
+		return errors.New("wrong number of arguments")
+	}
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: toks[1],
+		Region:  s.opts.Region,
+	})
+	if err != nil {
+		s.debug("failed to create ECS API object: %s", err)
+		return err
+	}
+	ctx, cancel := s.commandContext()
+	defer cancel()
+	to, err := ecs.ServiceTaskDefinitionWithContext(ctx, toks[2])
+	if err != nil {
+		return err
+	}
+	from, err := ecs.PreviousTaskDefinitionWithContext(ctx, to.Arn)
+	if err != nil {
+		return err
+	}
+	// environment values often hold credentials, keep them out of the chat
+	from, to = libecs.MaskEnvironment(from, to)
+
+	var out bytes.Buffer
+	if err := libecs.NewBasicServiceOutputer(s.opts.ShortArns).DisplayTaskDefinitionDiff(from, to, &out); err != nil {
+		s.debug("failed to display: %s", err)
+		return err
+	}
+	if _, err := s.kbc.SendMessageByConvID(conv.Id, "```%s```", out.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *BotServer) handleClusters(conv chat1.ConvSummary, msg chat1.MsgSummary) (err error) {
+	defer func() {
+		if err != nil {
+			if _, err := s.kbc.ReactByConvID(conv.Id, msg.Id, ":-1:"); err != nil {
+				s.debug("failed to react: %s", err)
+			}
+			s.kbc.SendMessageByConvID(conv.Id, "failed to list clusters: %s", err)
+		}
+	}()
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Region: s.opts.Region,
+	})
+	if err != nil {
+		s.debug("failed to create ECS API object: %s", err)
+		return err
+	}
+	ctx, cancel := s.commandContext()
+	defer cancel()
+	clusters, err := ecs.ListClustersWithContext(ctx)
+	if err != nil {
+		s.debug("failed to list clusters: %s", err)
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := libecs.NewBasicServiceOutputer(s.opts.ShortArns).DisplayClusters(clusters, &out); err != nil {
+		s.debug("failed to display: %s", err)
+		return err
+	}
+	if _, err := s.kbc.SendMessageByConvID(conv.Id, "```%s```", out.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *BotServer) handleGraph(conv chat1.ConvSummary, msg chat1.MsgSummary) (err error) {
 	defer func() {
 		if err != nil {
@@ -131,9 +241,15 @@ func (s *BotServer) handleGraph(conv chat1.ConvSummary, msg chat1.MsgSummary) (e
 		}
 	}()
 	toks := strings.Split(strings.Trim(msg.Content.Text.Body, " "), " ")
-	if len(toks) != 4 {
+	if len(toks) != 4 && len(toks) != 5 {
 		return errors.New("wrong number of arguments")
 	}
+	opts := libecs.GraphOptions{Renderer: s.opts.GraphRenderer}
+	if len(toks) == 5 {
+		if opts.Range, err = libecs.ParseTimeRange(toks[4]); err != nil {
+			return err
+		}
+	}
 	ecs, err := libecs.New(libecs.ECSConfig{
 		Cluster: toks[1],
 		Region:  s.opts.Region,
@@ -142,12 +258,14 @@ func (s *BotServer) handleGraph(conv chat1.ConvSummary, msg chat1.MsgSummary) (e
 		s.debug("failed to create ECS API object: %s", err)
 		return err
 	}
+	ctx, cancel := s.commandContext()
+	defer cancel()
 	var res io.Reader
 	switch toks[3] {
 	case "cpu":
-		res, err = ecs.GetServiceCPUGraph(toks[2], 24*time.Hour)
+		res, err = ecs.GetServiceGraphWithContext(ctx, toks[2], "CPUUtilization", opts)
 	case "mem":
-		res, err = ecs.GetServiceCPUGraph(toks[2], 24*time.Hour)
+		res, err = ecs.GetServiceGraphWithContext(ctx, toks[2], "MemoryUtilization", opts)
 	default:
 		return errors.New("unknown metric")
 	}
@@ -155,7 +273,11 @@ func (s *BotServer) handleGraph(conv chat1.ConvSummary, msg chat1.MsgSummary) (e
 		return err
 	}
 
-	file, err := ioutil.TempFile("", "graph")
+	ext := ".png"
+	if opts.Renderer == libecs.RendererSVG {
+		ext = ".svg"
+	}
+	file, err := ioutil.TempFile("", "graph*"+ext)
 	if err != nil {
 		return err
 	}
@@ -202,14 +324,16 @@ type runSpec struct {
 func (s *BotServer) sendReply(spec *runSpec) error {
 	var ecsInfo bytes.Buffer
 	greet := fmt.Sprintf("Thanks @%s! Loading cluster: *%s*", spec.author, spec.cluster)
-	if _, err := s.kbc.SendMessageByConvID(spec.conv.Id, greet); err != nil {
+	if _, err := s.kbc.SendMessageByConvID(spec.conv.Id, "%s", greet); err != nil {
 		return err
 	}
-	if err := s.runServiceOutput(spec.cluster, &ecsInfo); err != nil {
+	ctx, cancel := s.commandContext()
+	defer cancel()
+	if err := s.runServiceOutput(ctx, spec.cluster, &ecsInfo); err != nil {
 		return err
 	}
 	outputRes := fmt.Sprintf("```%s```", ecsInfo.String())
-	if _, err := s.kbc.SendMessageByConvID(spec.conv.Id, outputRes); err != nil {
+	if _, err := s.kbc.SendMessageByConvID(spec.conv.Id, "%s", outputRes); err != nil {
 		return err
 	}
 	if _, err := s.kbc.ReactByConvID(spec.conv.Id, spec.msg.Id, ":white_check_mark:"); err != nil {
@@ -256,6 +380,7 @@ func (s *BotServer) Start() (err error) {
 
 func mainInner() int {
 	var opts Options
+	var rendererName string
 
 	flag.StringVar(&opts.KeybaseLocation, "keybase", "keybase", "keybase command")
 	flag.StringVar(&opts.ClusterName, "cluster", "gregord", "cluster name")
@@ -263,7 +388,24 @@ func mainInner() int {
 	flag.StringVar(&opts.TeamName, "teamname", "", "Team to operate in")
 	flag.StringVar(&opts.Home, "home", "", "Home directory")
 	flag.BoolVar(&opts.ShortArns, "short-arns", true, "display only last part of ARN")
+	flag.DurationVar(&opts.CommandTimeout, "command-timeout", 2*time.Minute,
+		"deadline for answering a single command, 0 for none")
+	flag.BoolVar(&opts.Alarms, "alarms", false, "show firing CloudWatch alarms in service listings")
+	flag.StringVar(&opts.AlarmNamePrefix, "alarm-prefix", "", "only look at alarms whose names start with this")
+	flag.StringVar(&rendererName, "graph-renderer", "cloudwatch",
+		"how to draw graphs: cloudwatch, or png or svg to draw them locally")
 	flag.Parse()
+	if opts.CommandTimeout < 0 {
+		fmt.Printf("invalid command timeout: %s\n", opts.CommandTimeout)
+		return 3
+	}
+
+	renderer, err := libecs.ParseGraphRenderer(rendererName)
+	if err != nil {
+		fmt.Printf("invalid graph renderer: %s\n", err)
+		return 3
+	}
+	opts.GraphRenderer = renderer
 
 	bs := NewBotServer(opts)
 	if err := bs.Start(); err != nil {
@@ -14,17 +14,33 @@ func main() {
 }
 
 func mainInner() int {
-	var clusterName, region string
-	var shortArns bool
+	var clusterName, region, metricsName, alarmPrefix string
+	var shortArns, alarms bool
+	var concurrency int
 
 	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
 	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
 	flag.BoolVar(&shortArns, "short-arns", true, "display only last part of ARN")
+	flag.IntVar(&concurrency, "concurrency", 8, "maximum number of parallel AWS requests")
+	flag.StringVar(&metricsName, "task-metrics", "auto",
+		"per-task metrics source: auto, container-insights or instance")
+	flag.BoolVar(&alarms, "alarms", false, "show firing CloudWatch alarms for each service")
+	flag.StringVar(&alarmPrefix, "alarm-prefix", "", "only look at alarms whose names start with this")
 	flag.Parse()
 
+	taskMetrics, err := libecs.ParseTaskMetricsSource(metricsName)
+	if err != nil {
+		fmt.Printf("invalid task metrics source: %s\n", err)
+		return 3
+	}
+
 	ecs, err := libecs.New(libecs.ECSConfig{
-		Cluster: clusterName,
-		Region:  region,
+		Cluster:         clusterName,
+		Region:          region,
+		Concurrency:     concurrency,
+		TaskMetrics:     taskMetrics,
+		Alarms:          alarms,
+		AlarmNamePrefix: alarmPrefix,
 	})
 	if err != nil {
 		fmt.Printf("failed to create ECS API object: %s", err.Error())
@@ -38,7 +54,7 @@ func mainInner() int {
 
 	output := libecs.NewColorServiceOutputer(shortArns)
 	if err := output.DisplayServices(services, os.Stdout); err != nil {
-		fmt.Println("failed to display: %s", err.Error())
+		fmt.Printf("failed to display: %s", err.Error())
 		return 3
 	}
 
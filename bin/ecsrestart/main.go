@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmaxim/ecstools/internal/cli"
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, serviceName, region string
+	var yes bool
+	var timeout time.Duration
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&serviceName, "service", "gregord", "service name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	flag.DurationVar(&timeout, "timeout", 15*time.Minute, "how long to wait for the new deployment")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	if !yes && !cli.Confirm(fmt.Sprintf("Replace all tasks of %s?", serviceName)) {
+		fmt.Printf("aborted\n")
+		return 1
+	}
+	deployment, err := ecs.RestartService(serviceName)
+	if err != nil {
+		fmt.Printf("failed to restart service: %s\n", err)
+		return 3
+	}
+	fmt.Printf("started deployment %s of %s\n", deployment.ID, deployment.TaskDefinition)
+
+	last := -1
+	if err := ecs.WaitForDeployment(serviceName, deployment.ID, timeout, func(d libecs.Deployment) {
+		if d.RunningCount == last {
+			return
+		}
+		last = d.RunningCount
+		fmt.Printf("%s: %d/%d running, %d pending\n", d.ID, d.RunningCount, d.DesiredCount, d.PendingCount)
+	}); err != nil {
+		fmt.Printf("restart failed: %s\n", err)
+		return 3
+	}
+	fmt.Printf("%s restarted\n", serviceName)
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmaxim/ecstools/internal/cli"
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, serviceName, region, revision string
+	var dryRun, yes bool
+	var timeout time.Duration
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&serviceName, "service", "gregord", "service name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.StringVar(&revision, "revision", "",
+		"revision number or task definition to roll back to (default: the previous active revision)")
+	flag.BoolVar(&dryRun, "dry-run", false, "show the task definition diff without rolling back")
+	flag.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	flag.DurationVar(&timeout, "timeout", 15*time.Minute, "how long to wait for the rollback deployment")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	plan, err := ecs.PlanRollback(serviceName, revision)
+	if err != nil {
+		fmt.Printf("failed to plan rollback: %s\n", err)
+		return 3
+	}
+	fmt.Printf("%s: %s -> %s\n", plan.Service, plan.From.Name(), plan.To.Name())
+	output := libecs.NewColorServiceOutputer(true)
+	if err := output.DisplayTaskDefinitionDiff(plan.From, plan.To, os.Stdout); err != nil {
+		fmt.Printf("failed to display: %s\n", err)
+		return 3
+	}
+	if dryRun {
+		return 0
+	}
+	if !yes && !cli.Confirm(fmt.Sprintf("Roll %s back to %s?", plan.Service, plan.To.Name())) {
+		fmt.Printf("aborted\n")
+		return 1
+	}
+
+	last := -1
+	if _, err := ecs.RollbackService(serviceName, plan.To.Arn, timeout, func(d libecs.Deployment) {
+		if d.RunningCount == last {
+			return
+		}
+		last = d.RunningCount
+		fmt.Printf("%s: %d/%d running, %d pending\n", d.ID, d.RunningCount, d.DesiredCount, d.PendingCount)
+	}); err != nil {
+		fmt.Printf("rollback failed: %s\n", err)
+		return 3
+	}
+	fmt.Printf("%s rolled back to %s\n", plan.Service, plan.To.Name())
+	return 0
+}
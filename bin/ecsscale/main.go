@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmaxim/ecstools/internal/cli"
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, serviceName, region string
+	var count, minCount, maxCount int
+	var dryRun, yes, wait bool
+	var timeout time.Duration
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&serviceName, "service", "gregord", "service name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.IntVar(&count, "count", -1, "new desired count")
+	flag.IntVar(&minCount, "min", 0, "smallest desired count to accept")
+	flag.IntVar(&maxCount, "max", 0, "largest desired count to accept, 0 for no limit")
+	flag.BoolVar(&dryRun, "dry-run", false, "show the change without making it")
+	flag.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	flag.BoolVar(&wait, "wait", false, "wait for the service to reach a steady state")
+	flag.DurationVar(&timeout, "timeout", 10*time.Minute, "how long to wait with --wait")
+	flag.Parse()
+	if count < 0 {
+		fmt.Printf("please specify the new desired count with --count\n")
+		return 3
+	}
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster:         clusterName,
+		Region:          region,
+		MinDesiredCount: minCount,
+		MaxDesiredCount: maxCount,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	change, err := ecs.PlanScale(serviceName, count)
+	if err != nil {
+		fmt.Printf("invalid scale request: %s\n", err)
+		return 3
+	}
+	fmt.Printf("%s: desired count %d -> %d (%d running)\n", change.Service, change.From, change.To,
+		change.Running)
+	if dryRun {
+		return 0
+	}
+	if change.From == change.To {
+		fmt.Printf("nothing to do\n")
+		return 0
+	}
+	if !yes && !cli.Confirm(fmt.Sprintf("Scale %s to %d?", change.Service, change.To)) {
+		fmt.Printf("aborted\n")
+		return 1
+	}
+
+	if _, err := ecs.ScaleService(serviceName, count); err != nil {
+		fmt.Printf("failed to scale service: %s\n", err)
+		return 3
+	}
+	fmt.Printf("scaled %s to %d\n", change.Service, change.To)
+	if !wait {
+		return 0
+	}
+
+	if err := ecs.WaitForSteadyState(serviceName, timeout, func(svc libecs.Service) {
+		fmt.Printf("%s: %d running, %d pending\n", svc.Name, svc.RunningCount, svc.PendingCount)
+	}); err != nil {
+		fmt.Printf("failed waiting for steady state: %s\n", err)
+		return 3
+	}
+	fmt.Printf("%s reached a steady state\n", change.Service)
+	return 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmaxim/ecstools/internal/cli"
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, region, reason string
+	var yes bool
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.StringVar(&reason, "reason", "", "why the tasks are being stopped")
+	flag.BoolVar(&yes, "yes", false, "do not ask for confirmation")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] task-id...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return 3
+	}
+	if reason == "" {
+		fmt.Printf("please say why the tasks are being stopped with --reason\n")
+		return 3
+	}
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	var tasks []libecs.Task
+	for _, id := range flag.Args() {
+		task, err := ecs.ResolveTask(id)
+		if err != nil {
+			fmt.Printf("failed to find task: %s\n", err)
+			return 3
+		}
+		tasks = append(tasks, task)
+	}
+	output := libecs.NewColorServiceOutputer(true)
+	if err := output.DisplayTasks(tasks, os.Stdout); err != nil {
+		fmt.Printf("failed to display: %s\n", err)
+		return 3
+	}
+	if !yes && !cli.Confirm(fmt.Sprintf("Stop %d task(s)?", len(tasks))) {
+		fmt.Printf("aborted\n")
+		return 1
+	}
+
+	for _, task := range tasks {
+		if _, err := ecs.StopTask(task.Arn, reason); err != nil {
+			fmt.Printf("failed to stop task: %s\n", err)
+			return 3
+		}
+		fmt.Printf("stopped %s\n", task.Arn)
+	}
+	return 0
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/mmaxim/ecstools/libecs"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -15,19 +17,123 @@ func main() {
 	os.Exit(rc)
 }
 
+// terminalWidth is the width of the terminal on stdout, falling back to
+// $COLUMNS and then 80 columns.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
 func mainInner() int {
-	var clusterName, serviceName, region string
+	var clusterName, serviceName, region, rangeName, specFile, rendererName, inputFile string
+	var period time.Duration
+	var width, height int
+	var ascii, csv bool
 
 	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
 	flag.StringVar(&serviceName, "service", "gregord", "service name")
 	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.StringVar(&rangeName, "range", "24h",
+		"time window, relative (1h, 6h, 7d) or absolute (2024-05-01/2024-05-03 or RFC 3339 start/end)")
+	flag.DurationVar(&period, "period", 0, "length of each datapoint (default: picked from the range)")
+	flag.IntVar(&width, "width", 0,
+		"graph width in pixels, or columns with --ascii (default: CloudWatch's, or the terminal width)")
+	flag.IntVar(&height, "height", 0, "graph height in pixels, or rows with --ascii (default: CloudWatch's, or 20)")
+	flag.BoolVar(&ascii, "ascii", false, "draw the graph as text in the terminal instead of writing a PNG")
+	flag.BoolVar(&csv, "csv", false, "write the graph's datapoints as CSV instead of writing a PNG")
+	flag.StringVar(&specFile, "spec", "", "JSON graph spec file to draw instead of a cpu or mem graph")
+	flag.StringVar(&rendererName, "renderer", "cloudwatch",
+		"how to draw the graph: cloudwatch, or png or svg to draw it locally")
+	flag.StringVar(&inputFile, "input", "",
+		"draw datapoints from a CSV file written by --csv instead of fetching them (implies a local renderer)")
 	flag.Parse()
 	args := flag.Args()
-	if len(args) != 1 {
-		fmt.Printf("wrong number of arguments, please specify a graph type (cpu or mem)\n")
+	if ascii && csv {
+		fmt.Printf("only one of --ascii and --csv can be given\n")
+		return 3
+	}
+
+	renderer, err := libecs.ParseGraphRenderer(rendererName)
+	if err != nil {
+		fmt.Printf("invalid renderer: %s\n", err)
 		return 3
 	}
-	typ := args[0]
+
+	var spec libecs.GraphSpec
+	var opts libecs.GraphOptions
+	if specFile != "" {
+		if len(args) != 0 {
+			fmt.Printf("a graph type cannot be given with --spec\n")
+			return 3
+		}
+		if spec, opts, err = libecs.LoadGraphSpec(specFile); err != nil {
+			fmt.Printf("failed to load graph spec: %s\n", err)
+			return 3
+		}
+	} else if inputFile == "" {
+		if len(args) != 1 {
+			fmt.Printf("wrong number of arguments, please specify a graph type (cpu or mem)\n")
+			return 3
+		}
+		switch args[0] {
+		case "cpu":
+			spec = libecs.ServiceGraphSpec(serviceName, "CPUUtilization")
+		case "mem":
+			spec = libecs.ServiceGraphSpec(serviceName, "MemoryUtilization")
+		default:
+			fmt.Printf("unknown graph type: %s\n", args[0])
+			return 3
+		}
+	}
+
+	// Flags given on the command line override the spec file
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if specFile == "" || set["range"] {
+		if opts.Range, err = libecs.ParseTimeRange(rangeName); err != nil {
+			fmt.Printf("invalid range: %s\n", err)
+			return 3
+		}
+	}
+	if specFile == "" || set["period"] {
+		opts.Period = period
+	}
+	if specFile == "" || set["width"] {
+		opts.Width = width
+	}
+	if specFile == "" || set["height"] {
+		opts.Height = height
+	}
+	if specFile == "" || set["renderer"] {
+		opts.Renderer = renderer
+	}
+	if inputFile != "" {
+		if ascii || csv {
+			fmt.Printf("--input cannot be combined with --ascii or --csv\n")
+			return 3
+		}
+		if err := drawInput(inputFile, spec, opts); err != nil {
+			fmt.Printf("failed to draw %s: %s\n", inputFile, err)
+			return 3
+		}
+		return 0
+	}
+	if ascii {
+		// The sizes are for the terminal, not CloudWatch
+		width, height = opts.Width, opts.Height
+		opts.Width, opts.Height = 0, 0
+		if width == 0 {
+			width = terminalWidth()
+		}
+		if height == 0 {
+			height = 20
+		}
+	}
 
 	ecs, err := libecs.New(libecs.ECSConfig{
 		Cluster: clusterName,
@@ -38,16 +144,41 @@ func mainInner() int {
 		return 3
 	}
 
-	var res io.Reader
-	switch typ {
-	case "cpu":
-		res, err = ecs.GetServiceCPUGraph(serviceName, time.Hour*24)
-	case "mem":
-		res, err = ecs.GetServiceMemoryGraph(serviceName, time.Hour*24)
-	default:
-		fmt.Printf("unknown graph type: %s\n", typ)
-		return 3
+	if csv {
+		series, err := ecs.GetMetricSeries(spec, opts)
+		if err != nil {
+			fmt.Printf("failed to get metrics: %s\n", err)
+			return 3
+		}
+		if err := libecs.WriteMetricSeriesCSV(os.Stdout, series); err != nil {
+			fmt.Printf("failed to write result: %s\n", err)
+			return 3
+		}
+		return 0
+	}
+
+	if specFile == "" {
+		if spec.Markers, err = ecs.ServiceDeploymentMarkers(serviceName, opts.Range); err != nil {
+			fmt.Printf("failed to get deployments: %s\n", err)
+			return 3
+		}
+	}
+
+	if ascii {
+		chart, err := ecs.GetChart(spec, opts)
+		if err != nil {
+			fmt.Printf("failed to get metrics: %s\n", err)
+			return 3
+		}
+		chart.Width, chart.Height = width, height
+		if err := libecs.WriteChartASCII(os.Stdout, chart); err != nil {
+			fmt.Printf("failed to write result: %s\n", err)
+			return 3
+		}
+		return 0
 	}
+
+	res, err := ecs.GetGraph(spec, opts)
 	if err != nil {
 		fmt.Printf("failed to get graph: %s\n", err)
 		return 3
@@ -59,3 +190,30 @@ func mainInner() int {
 	}
 	return 0
 }
+
+// drawInput draws recorded datapoints locally, without any AWS access.
+func drawInput(path string, spec libecs.GraphSpec, opts libecs.GraphOptions) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	series, err := libecs.ReadMetricSeriesCSV(f)
+	if err != nil {
+		return err
+	}
+	renderer := opts.Renderer
+	if renderer == "" || renderer == libecs.RendererCloudWatch {
+		renderer = libecs.RendererPNG
+	}
+	return libecs.WriteChart(os.Stdout, renderer, libecs.Chart{
+		Title:      spec.Title,
+		Series:     series,
+		Thresholds: spec.Thresholds,
+		Markers:    spec.Markers,
+		YMin:       spec.YMin,
+		YMax:       spec.YMax,
+		Width:      opts.Width,
+		Height:     opts.Height,
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func main() {
+	rc := mainInner()
+	os.Exit(rc)
+}
+
+func mainInner() int {
+	var clusterName, serviceName, region, fromName, toName string
+
+	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
+	flag.StringVar(&serviceName, "service", "gregord", "service name")
+	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
+	flag.StringVar(&fromName, "from", "", "task definition to diff from (default: revision before --to)")
+	flag.StringVar(&toName, "to", "", "task definition to diff to (default: the service's task definition)")
+	flag.Parse()
+
+	ecs, err := libecs.New(libecs.ECSConfig{
+		Cluster: clusterName,
+		Region:  region,
+	})
+	if err != nil {
+		fmt.Printf("failed to create ECS API object: %s\n", err)
+		return 3
+	}
+
+	var to, from libecs.TaskDefinition
+	if toName != "" {
+		to, err = ecs.DescribeTaskDefinition(toName)
+	} else {
+		to, err = ecs.ServiceTaskDefinition(serviceName)
+	}
+	if err != nil {
+		fmt.Printf("failed to get task definition: %s\n", err)
+		return 3
+	}
+	if fromName != "" {
+		from, err = ecs.DescribeTaskDefinition(fromName)
+	} else {
+		from, err = ecs.PreviousTaskDefinition(to.Arn)
+	}
+	if err != nil {
+		fmt.Printf("failed to get previous task definition: %s\n", err)
+		return 3
+	}
+
+	output := libecs.NewColorServiceOutputer(true)
+	if err := output.DisplayTaskDefinitionDiff(from, to, os.Stdout); err != nil {
+		fmt.Printf("failed to display: %s\n", err)
+		return 3
+	}
+
+	return 0
+}
@@ -14,30 +14,58 @@ func main() {
 }
 
 func mainInner() int {
-	var clusterName, serviceName, region string
-	var shortArns bool
+	var clusterName, serviceName, region, statusName, metricsName string
+	var shortArns, detail bool
 
 	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
 	flag.StringVar(&serviceName, "service", "gregord", "srvice name")
 	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
 	flag.BoolVar(&shortArns, "short-arns", true, "display only last part of ARN")
+	flag.BoolVar(&detail, "detail", false, "show container level detail for each task")
+	flag.StringVar(&statusName, "status", "running", "tasks to list: running, stopped or all")
+	flag.StringVar(&metricsName, "task-metrics", "auto",
+		"per-task metrics source: auto, container-insights or instance")
 	flag.Parse()
 
+	status, err := libecs.ParseTaskStatus(statusName)
+	if err != nil {
+		fmt.Printf("invalid status: %s\n", err)
+		return 3
+	}
+	taskMetrics, err := libecs.ParseTaskMetricsSource(metricsName)
+	if err != nil {
+		fmt.Printf("invalid task metrics source: %s\n", err)
+		return 3
+	}
+
 	ecs, err := libecs.New(libecs.ECSConfig{
-		Cluster: clusterName,
-		Region:  region,
+		Cluster:     clusterName,
+		Region:      region,
+		TaskMetrics: taskMetrics,
 	})
 	if err != nil {
 		fmt.Printf("failed to create ECS API object: %s\n", err)
 		return 3
 	}
 
-	tasks, err := ecs.ListTasks(serviceName)
+	tasks, err := ecs.ListTasksWithStatus(serviceName, status)
 	if err != nil {
 		fmt.Printf("failed to list services: %s\n", err)
 	}
 
 	output := libecs.NewColorServiceOutputer(shortArns)
+	if detail {
+		for i, task := range tasks {
+			if i > 0 {
+				fmt.Println()
+			}
+			if err := output.DisplayTaskDetail(task, os.Stdout); err != nil {
+				fmt.Printf("failed to display: %s\n", err)
+				return 3
+			}
+		}
+		return 0
+	}
 	if err := output.DisplayTasks(tasks, os.Stdout); err != nil {
 		fmt.Printf("failed to display: %s\n", err)
 		return 3
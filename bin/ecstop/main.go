@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 	"github.com/mmaxim/ecstools/libecs"
@@ -32,6 +34,9 @@ func getInstanceID(task libecs.Task) string {
 	if task.InstanceMetrics != nil {
 		return task.InstanceMetrics.ID
 	}
+	if task.LaunchType == awsecs.LaunchTypeFargate {
+		return "fargate"
+	}
 	return "n/a"
 }
 
@@ -42,38 +47,98 @@ func getInstanceCPU(task libecs.Task) string {
 	return "n/a"
 }
 
-func renderSvcs(ecs *libecs.ECS) (string, string) {
+func getTaskCPU(task libecs.Task) string {
+	if task.TaskMetrics != nil {
+		return fmt.Sprintf("%f%%", task.TaskMetrics.CPUPercent())
+	}
+	return "n/a"
+}
 
-	services, err := ecs.ListServices()
+func getTaskMemory(task libecs.Task) string {
+	if task.TaskMetrics != nil {
+		return fmt.Sprintf("%f%%", task.TaskMetrics.MemoryPercent())
+	}
+	return "n/a"
+}
+
+func colorDeployState(svc libecs.Service) string {
+	switch {
+	case svc.DeploymentFailed():
+		return "[" + svc.DeployState() + "](fg-red)"
+	case svc.DeploymentInProgress():
+		return "[" + svc.DeployState() + "](fg-yellow)"
+	default:
+		return svc.DeployState()
+	}
+}
+
+func renderSvcs(ctx context.Context, ecs *libecs.ECS) (string, string) {
+
+	services, err := ecs.ListServicesWithContext(ctx)
 	if err != nil {
 		fmt.Printf("failed to list services: %s", err.Error())
 	}
 
 	buffer := &bytes.Buffer{}
 	w := tabwriter.NewWriter(buffer, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "[Name\tRun\tPend\tTask\tCPU%%\tMemory%%](fg-red)\n")
+	// Deploy goes last since its color markup would otherwise throw off the
+	// column alignment
+	// Alarms are only there when --alarms looked them up
+	alarms := false
+	for _, s := range services {
+		alarms = alarms || s.Alarms != nil
+	}
+	if alarms {
+		fmt.Fprintf(w, "[Name\tRun\tPend\tTask\tCPU%%\tMemory%%\tAlarms\tDeploy](fg-red)\n")
+	} else {
+		fmt.Fprintf(w, "[Name\tRun\tPend\tTask\tCPU%%\tMemory%%\tDeploy](fg-red)\n")
+	}
 	for _, s := range services {
-		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%f\t%f\n", s.Name, s.RunningCount, s.PendingCount,
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%f\t%f\t", s.Name, s.RunningCount, s.PendingCount,
 			truncateARN(s.TaskDefinition, true), s.Metrics.CPU, s.Metrics.Memory)
+		if alarms {
+			fmt.Fprintf(w, "%s\t", s.AlarmState())
+		}
+		fmt.Fprintf(w, "%s\n", colorDeployState(s))
+	}
+	w.Flush()
+
+	// Everything that is currently paging goes right under the services
+	firing := false
+	for _, s := range services {
+		for _, a := range s.FiringAlarms() {
+			if !firing {
+				fmt.Fprintf(w, "\n[Service\tAlarms Firing\tSince\tReason](fg-red)\n")
+				firing = true
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, libecs.EscapeTags(a.Name),
+				a.UpdatedAt.Format("01-02-2006 15:04"), libecs.EscapeTags(a.Reason))
+		}
 	}
 	w.Flush()
 	loreley.DelimLeft = "<"
 	loreley.DelimRight = ">"
-	svcResult, _ := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
+	svcResult, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
+	if err != nil {
+		svcResult = fmt.Sprintf("failed to format services: %s", err)
+	}
 
 	buffer.Reset()
 	w = tabwriter.NewWriter(buffer, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "[Service\tStatus\tTask\tCreated At\tInstance ID\tInstance CPU%%](fg-red)\n")
+	fmt.Fprintf(w, "[Service\tStatus\tTask\tCreated At\tInstance ID\tInst CPU%%\tTask CPU%%\tTask Mem%%](fg-red)\n")
 	for _, svc := range services {
 		for _, task := range svc.Tasks {
 			ca := task.CreatedAt.Format("01-02-2006 15:04")
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, task.Status,
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, task.Status,
 				truncateARN(task.TaskDefinition, true), ca, getInstanceID(task),
-				getInstanceCPU(task))
+				getInstanceCPU(task), getTaskCPU(task), getTaskMemory(task))
 		}
 	}
 	w.Flush()
-	taskResult, _ := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
+	taskResult, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
+	if err != nil {
+		taskResult = fmt.Sprintf("failed to format tasks: %s", err)
+	}
 
 	return svcResult, taskResult
 }
@@ -83,14 +148,21 @@ type workerRes struct {
 	taskRes string
 }
 
-func startRefreshWorker(workCh chan struct{}, ecs *libecs.ECS, svcui *widgets.Paragraph) (resCh chan workerRes) {
+func startRefreshWorker(ctx context.Context, workCh chan struct{}, ecs *libecs.ECS,
+	svcui *widgets.Paragraph) (resCh chan workerRes) {
 	resCh = make(chan workerRes, 1)
 	go func() {
 		for range workCh {
+			if ctx.Err() != nil {
+				return
+			}
 			var res workerRes
 			svcui.Title = "Services (refreshing)"
 			ui.Render(svcui)
-			res.svcRes, res.taskRes = renderSvcs(ecs)
+			res.svcRes, res.taskRes = renderSvcs(ctx, ecs)
+			if ctx.Err() != nil {
+				return
+			}
 			svcui.Title = "Services"
 			ui.Render(svcui)
 			resCh <- res
@@ -106,23 +178,43 @@ func mainInner() int {
 	}
 	defer ui.Close()
 
-	var clusterName, region string
-	var shortArns bool
+	var clusterName, region, metricsName, alarmPrefix string
+	var shortArns, alarms bool
+	var concurrency int
 
 	flag.StringVar(&clusterName, "cluster", "gregord", "cluster name")
 	flag.StringVar(&region, "region", "us-east-1", "AWS region name")
 	flag.BoolVar(&shortArns, "short-arns", true, "display only last part of ARN")
+	flag.IntVar(&concurrency, "concurrency", 8, "maximum number of parallel AWS requests")
+	flag.StringVar(&metricsName, "task-metrics", "auto",
+		"per-task metrics source: auto, container-insights or instance")
+	flag.BoolVar(&alarms, "alarms", false, "show firing CloudWatch alarms for each service")
+	flag.StringVar(&alarmPrefix, "alarm-prefix", "", "only look at alarms whose names start with this")
 	flag.Parse()
 
+	taskMetrics, err := libecs.ParseTaskMetricsSource(metricsName)
+	if err != nil {
+		fmt.Printf("invalid task metrics source: %s\n", err)
+		return 3
+	}
+
 	ecs, err := libecs.New(libecs.ECSConfig{
-		Cluster: clusterName,
-		Region:  region,
+		Cluster:         clusterName,
+		Region:          region,
+		Concurrency:     concurrency,
+		TaskMetrics:     taskMetrics,
+		Alarms:          alarms,
+		AlarmNamePrefix: alarmPrefix,
 	})
 	if err != nil {
 		fmt.Printf("failed to create ECS API object: %s", err.Error())
 		return 3
 	}
 
+	// Cancel any in-flight refresh when we exit
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	workCh := make(chan struct{}, 1)
 	width := 120
 
@@ -134,7 +226,7 @@ func mainInner() int {
 	svcui.SetRect(0, 0, width, 40)
 	svcui.TitleStyle.Fg = ui.ColorYellow
 
-	resCh := startRefreshWorker(workCh, ecs, svcui)
+	resCh := startRefreshWorker(ctx, workCh, ecs, svcui)
 
 	taskui := widgets.NewParagraph()
 	taskui.PaddingLeft = 3
@@ -7,6 +7,7 @@ require (
 	github.com/gizak/termui/v3 v3.1.0
 	github.com/keybase/go-keybase-chat-bot v0.0.0-20250106203511-859265729a56
 	github.com/reconquest/loreley v0.0.0-20211011075601-29b1d7b0ad91
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 )
 
 require (
@@ -19,6 +20,5 @@ require (
 	github.com/stretchr/testify v1.10.0 // indirect
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
 	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
-	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,174 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// Alarm is a CloudWatch metric alarm watching one of a service's metrics.
+type Alarm struct {
+	Name string
+	Arn  string
+	// State is OK, ALARM or INSUFFICIENT_DATA.
+	State     string
+	Reason    string
+	UpdatedAt time.Time
+}
+
+// Firing reports whether the alarm is in the ALARM state.
+func (a Alarm) Firing() bool {
+	return a.State == cloudwatch.StateValueAlarm
+}
+
+// FiringAlarms returns the service's alarms in the ALARM state.
+func (s Service) FiringAlarms() []Alarm {
+	var res []Alarm
+	for _, a := range s.Alarms {
+		if a.Firing() {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// AlarmState summarizes the service's alarms as how many are firing, or ok.
+// It is n/a if alarms were not looked up.
+func (s Service) AlarmState() string {
+	switch {
+	case s.Alarms == nil:
+		return "n/a"
+	case len(s.FiringAlarms()) > 0:
+		return fmt.Sprintf("%d firing", len(s.FiringAlarms()))
+	default:
+		return "ok"
+	}
+}
+
+func newAlarm(a *cloudwatch.MetricAlarm) Alarm {
+	return Alarm{
+		Name:      aws.StringValue(a.AlarmName),
+		Arn:       aws.StringValue(a.AlarmArn),
+		State:     aws.StringValue(a.StateValue),
+		Reason:    aws.StringValue(a.StateReason),
+		UpdatedAt: aws.TimeValue(a.StateUpdatedTimestamp).Local(),
+	}
+}
+
+// targetGroupDimension returns the TargetGroup dimension value load
+// balancer metrics use for a target group ARN, e.g.
+// targetgroup/my-targets/73e2d6bc24d8a067.
+func targetGroupDimension(arn string) string {
+	return arn[strings.LastIndex(arn, ":")+1:]
+}
+
+// describeAlarms fetches the metric alarms matching ECSConfig.AlarmNamePrefix,
+// only those in the given state unless it is empty. DescribeAlarms cannot
+// filter on dimensions, so matching them to services is left to the caller.
+func (e *ECS) describeAlarms(ctx context.Context, state string) ([]*cloudwatch.MetricAlarm, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	input := &cloudwatch.DescribeAlarmsInput{
+		MaxRecords: aws.Int64(100),
+	}
+	if e.config.AlarmNamePrefix != "" {
+		input.AlarmNamePrefix = aws.String(e.config.AlarmNamePrefix)
+	}
+	if state != "" {
+		input.StateValue = aws.String(state)
+	}
+	var res []*cloudwatch.MetricAlarm
+	err := e.cloudwatch.DescribeAlarmsPagesWithContext(ctx, input, func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+		res = append(res, page.MetricAlarms...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// alarmDimensions returns the dimensions of each metric an alarm watches,
+// including those inside a metric math expression.
+func alarmDimensions(a *cloudwatch.MetricAlarm) []map[string]string {
+	toMap := func(dims []*cloudwatch.Dimension) map[string]string {
+		res := make(map[string]string, len(dims))
+		for _, d := range dims {
+			res[aws.StringValue(d.Name)] = aws.StringValue(d.Value)
+		}
+		return res
+	}
+	var res []map[string]string
+	if len(a.Dimensions) > 0 {
+		res = append(res, toMap(a.Dimensions))
+	}
+	for _, q := range a.Metrics {
+		if q.MetricStat != nil && q.MetricStat.Metric != nil {
+			res = append(res, toMap(q.MetricStat.Metric.Dimensions))
+		}
+	}
+	return res
+}
+
+// serviceAlarms picks out the alarms on the service's ECS metrics or on its
+// target groups, firing ones first.
+func (e *ECS) serviceAlarms(alarms []*cloudwatch.MetricAlarm, svc Service) []Alarm {
+	targetGroups := make(map[string]bool, len(svc.TargetGroups))
+	for _, arn := range svc.TargetGroups {
+		targetGroups[targetGroupDimension(arn)] = true
+	}
+	res := []Alarm{}
+	for _, a := range alarms {
+		for _, dims := range alarmDimensions(a) {
+			if (dims["ClusterName"] == e.cluster() && dims["ServiceName"] == svc.Name) ||
+				targetGroups[dims["TargetGroup"]] {
+				res = append(res, newAlarm(a))
+				break
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Firing() != res[j].Firing() {
+			return res[i].Firing()
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// fillServiceAlarms finds the firing alarms for all of the given services
+// with a single listing of the account's firing alarms.
+func (e *ECS) fillServiceAlarms(ctx context.Context, svcs []*Service) error {
+	alarms, err := e.describeAlarms(ctx, cloudwatch.StateValueAlarm)
+	if err != nil {
+		return err
+	}
+	for _, svc := range svcs {
+		svc.Alarms = e.serviceAlarms(alarms, *svc)
+	}
+	return nil
+}
+
+// ServiceAlarms returns the CloudWatch alarms in any state whose dimensions
+// name the service in this cluster, or one of the service's load balancer
+// target groups, firing ones first.
+func (e *ECS) ServiceAlarms(service string) ([]Alarm, error) {
+	return e.ServiceAlarmsWithContext(context.Background(), service)
+}
+
+func (e *ECS) ServiceAlarmsWithContext(ctx context.Context, service string) ([]Alarm, error) {
+	svc, err := e.describeService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	alarms, err := e.describeAlarms(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return e.serviceAlarms(alarms, newService(svc)), nil
+}
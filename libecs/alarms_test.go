@@ -0,0 +1,109 @@
+package libecs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestAlarmState(t *testing.T) {
+	alarm := func(state string) libecs.Alarm {
+		return libecs.Alarm{State: state}
+	}
+	cases := []struct {
+		name   string
+		alarms []libecs.Alarm
+		want   string
+		firing int
+	}{
+		{name: "not looked up", want: "n/a"},
+		{name: "none", alarms: []libecs.Alarm{}, want: "ok"},
+		{name: "ok", alarms: []libecs.Alarm{alarm("OK"), alarm("OK")}, want: "ok"},
+		{name: "firing", alarms: []libecs.Alarm{alarm("ALARM"), alarm("INSUFFICIENT_DATA"), alarm("ALARM")},
+			want: "2 firing", firing: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := libecs.Service{Alarms: c.alarms}
+			if got := svc.AlarmState(); got != c.want {
+				t.Errorf("AlarmState() = %s, want %s", got, c.want)
+			}
+			if got := len(svc.FiringAlarms()); got != c.firing {
+				t.Errorf("FiringAlarms() has %d alarms, want %d", got, c.firing)
+			}
+		})
+	}
+}
+
+func TestListServicesAlarms(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := b.Calls("DescribeAlarms"); calls != 0 {
+		t.Errorf("DescribeAlarms called %d times with alarms off", calls)
+	}
+	for _, s := range services {
+		if state := s.AlarmState(); state != "n/a" {
+			t.Errorf("%s alarm state = %s with alarms off, want n/a", s.Name, state)
+		}
+	}
+
+	e, b = newECS(t, libecs.ECSConfig{Alarms: true})
+	services, err = e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := b.Calls("DescribeAlarms"); calls != 1 {
+		t.Errorf("DescribeAlarms called %d times, want one listing for all services", calls)
+	}
+	want := map[string]string{"web": "1 firing", "worker": "ok", "api": "ok"}
+	for _, s := range services {
+		if state := s.AlarmState(); state != want[s.Name] {
+			t.Errorf("%s alarm state = %s, want %s", s.Name, state, want[s.Name])
+		}
+	}
+}
+
+func TestServiceAlarms(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		service string
+		want    []string
+	}{
+		// Firing alarms come first.
+		{"web", []string{"prod-web-high-cpu", "prod-web-5xx"}},
+		{"api", []string{"prod-api-memory-headroom"}},
+		{"worker", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.service, func(t *testing.T) {
+			alarms, err := e.ServiceAlarms(c.service)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, a := range alarms {
+				got = append(got, a.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("alarms = %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if _, err := e.ServiceAlarms("missing"); err == nil {
+		t.Errorf("ServiceAlarms of a missing service succeeded")
+	}
+
+	e, _ = newECS(t, libecs.ECSConfig{AlarmNamePrefix: "prod-web-"})
+	alarms, err := e.ServiceAlarms("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alarms) != 0 {
+		t.Errorf("api alarms = %+v, want none outside the name prefix", alarms)
+	}
+}
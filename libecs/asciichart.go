@@ -0,0 +1,185 @@
+package libecs
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// chartMarkers draw each series in turn. The first series is drawn last so
+// it stays on top where lines cross.
+var chartMarkers = []rune{'*', '+', 'o', '#', 'x', '@', '%', '='}
+
+// minChartWidth and minChartHeight are the smallest plot areas, not counting
+// axes and labels, that WriteASCIIChart will draw.
+const (
+	minChartWidth  = 10
+	minChartHeight = 3
+)
+
+func chartBounds(series []MetricSeries) (time.Time, time.Time, float64, float64, bool) {
+	var start, end time.Time
+	lo, hi := math.Inf(1), math.Inf(-1)
+	found := false
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !found || p.Time.Before(start) {
+				start = p.Time
+			}
+			if !found || p.Time.After(end) {
+				end = p.Time
+			}
+			lo = math.Min(lo, p.Value)
+			hi = math.Max(hi, p.Value)
+			found = true
+		}
+	}
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+	return start, end, lo, hi, found
+}
+
+// interpolate returns the series' value at t, drawing straight lines between
+// points, and false outside of the series' data.
+func interpolate(points []MetricPoint, t time.Time) (float64, bool) {
+	for i := range points {
+		if points[i].Time.Equal(t) {
+			return points[i].Value, true
+		}
+		if points[i].Time.After(t) {
+			if i == 0 {
+				return 0, false
+			}
+			prev := points[i-1]
+			frac := float64(t.Sub(prev.Time)) / float64(points[i].Time.Sub(prev.Time))
+			return prev.Value + frac*(points[i].Value-prev.Value), true
+		}
+	}
+	return 0, false
+}
+
+// WriteASCIIChart draws the series as a line chart using plain characters,
+// fitting it into width columns and height rows of plot area plus axes,
+// labels and a legend.
+func WriteASCIIChart(out io.Writer, title string, series []MetricSeries, width, height int) error {
+	return WriteChartASCII(out, Chart{
+		Title:  title,
+		Series: series,
+		Width:  width,
+		Height: height,
+	})
+}
+
+// WriteChartASCII draws the chart's series and markers with plain characters,
+// as WriteASCIIChart does. Width and Height are in columns and rows, and
+// thresholds and fixed axes are not drawn.
+func WriteChartASCII(out io.Writer, chart Chart) error {
+	series, width, height := chart.Series, chart.Width, chart.Height
+	_, _, lo, hi, ok := chartBounds(series)
+	if chart.Title != "" {
+		fmt.Fprintf(out, "%s\n", chart.Title)
+	}
+	if !ok {
+		fmt.Fprintf(out, "no data\n")
+		return nil
+	}
+	start, end, _ := chartWindow(chart)
+
+	labelWidth := len(fmt.Sprintf("%.2f", hi))
+	if l := len(fmt.Sprintf("%.2f", lo)); l > labelWidth {
+		labelWidth = l
+	}
+	plotWidth := width - labelWidth - 2
+	if plotWidth < minChartWidth {
+		plotWidth = minChartWidth
+	}
+	if height < minChartHeight {
+		height = minChartHeight
+	}
+
+	grid := make([][]rune, height)
+	for r := range grid {
+		grid[r] = []rune(strings.Repeat(" ", plotWidth))
+	}
+	rowOf := func(v float64) int {
+		return height - 1 - int(math.Round((v-lo)/(hi-lo)*float64(height-1)))
+	}
+	span := end.Sub(start)
+	for i := len(series) - 1; i >= 0; i-- {
+		marker := chartMarkers[i%len(chartMarkers)]
+		prevRow := -1
+		for c := 0; c < plotWidth; c++ {
+			t := start
+			if plotWidth > 1 {
+				t = start.Add(time.Duration(float64(span) * float64(c) / float64(plotWidth-1)))
+			}
+			v, ok := interpolate(series[i].Points, t)
+			if !ok {
+				prevRow = -1
+				continue
+			}
+			row := rowOf(v)
+			// Fill in steep climbs and drops so the line stays connected
+			from, to := row, row
+			if prevRow >= 0 {
+				if prevRow < row {
+					from = prevRow + 1
+				} else if prevRow > row {
+					to = prevRow - 1
+				}
+			}
+			for r := from; r <= to; r++ {
+				grid[r][c] = marker
+			}
+			prevRow = row
+		}
+	}
+
+	// Markers go behind the lines, so only fill in blank cells
+	var markers []GraphMarker
+	for _, m := range chart.Markers {
+		if m.Time.Before(start) || m.Time.After(end) {
+			continue
+		}
+		markers = append(markers, m)
+		c := 0
+		if span > 0 {
+			c = int(math.Round(float64(m.Time.Sub(start)) / float64(span) * float64(plotWidth-1)))
+		}
+		for r := range grid {
+			if grid[r][c] == ' ' {
+				grid[r][c] = '|'
+			}
+		}
+	}
+
+	for r, line := range grid {
+		label := ""
+		if r == 0 || r == height-1 || r%4 == 0 {
+			label = fmt.Sprintf("%.2f", hi-(hi-lo)*float64(r)/float64(height-1))
+		}
+		fmt.Fprintf(out, "%*s |%s\n", labelWidth, label, string(line))
+	}
+	fmt.Fprintf(out, "%s +%s\n", strings.Repeat(" ", labelWidth), strings.Repeat("-", plotWidth))
+
+	const timeFormat = "01-02 15:04"
+	left, right := start.Local().Format(timeFormat), end.Local().Format(timeFormat)
+	gap := plotWidth - len(left) - len(right)
+	if gap < 1 {
+		gap = 1
+	}
+	fmt.Fprintf(out, "%s  %s%s%s\n", strings.Repeat(" ", labelWidth), left, strings.Repeat(" ", gap), right)
+
+	var legend []string
+	for i, s := range series {
+		legend = append(legend, fmt.Sprintf("%c %s", chartMarkers[i%len(chartMarkers)], s.Label))
+	}
+	fmt.Fprintf(out, "%s\n", strings.Join(legend, "   "))
+	for _, m := range markers {
+		fmt.Fprintf(out, "| %s %s\n", m.Time.Local().Format(timeFormat), m.Label)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package libecs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Chart is everything the local renderers draw. Width and Height are in
+// pixels and default to defaultChartWidth by defaultChartHeight.
+type Chart struct {
+	Title      string
+	Series     []MetricSeries
+	Thresholds []GraphThreshold
+	Markers    []GraphMarker
+	YMin       *float64
+	YMax       *float64
+	// Start and End fix the time axis, normally to the requested window.
+	// Zero fits it to the data.
+	Start  time.Time
+	End    time.Time
+	Width  int
+	Height int
+}
+
+// chartWindow returns the span of the chart's time axis, and false if there
+// is nothing to draw in it.
+func chartWindow(chart Chart) (time.Time, time.Time, bool) {
+	start, end, _, _, ok := chartBounds(chart.Series)
+	if !chart.Start.IsZero() && !chart.End.IsZero() {
+		return chart.Start, chart.End, true
+	}
+	return start, end, ok
+}
+
+// GraphRenderer selects what draws a graph: CloudWatch's
+// GetMetricWidgetImage, or one of the local renderers working from the
+// metric series.
+type GraphRenderer string
+
+const (
+	RendererCloudWatch GraphRenderer = "cloudwatch"
+	RendererPNG        GraphRenderer = "png"
+	RendererSVG        GraphRenderer = "svg"
+)
+
+func ParseGraphRenderer(s string) (GraphRenderer, error) {
+	switch renderer := GraphRenderer(strings.ToLower(s)); renderer {
+	case RendererCloudWatch, RendererPNG, RendererSVG:
+		return renderer, nil
+	}
+	return "", fmt.Errorf("unknown graph renderer: %s", s)
+}
+
+// WriteChart draws the chart with a local renderer.
+func WriteChart(out io.Writer, renderer GraphRenderer, chart Chart) error {
+	switch renderer {
+	case RendererPNG:
+		return WriteChartPNG(out, chart)
+	case RendererSVG:
+		return WriteChartSVG(out, chart)
+	}
+	return fmt.Errorf("not a local graph renderer: %s", renderer)
+}
+
+// renderChart draws the chart into memory, for the graph functions that
+// return a reader.
+func renderChart(renderer GraphRenderer, chart Chart) (io.Reader, error) {
+	var buf bytes.Buffer
+	if err := WriteChart(&buf, renderer, chart); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+const (
+	defaultChartWidth  = 600
+	defaultChartHeight = 400
+	// chartCharWidth and chartCharHeight are the size of a character of
+	// chart text in pixels, including spacing.
+	chartCharWidth  = 6
+	chartCharHeight = 9
+	chartPadding    = 8
+	chartTimeFormat = "01-02 15:04"
+
+	defaultMarkerColor = "#7f7f7f"
+)
+
+// chartColors are used for series in order, then reused.
+var chartColors = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f",
+}
+
+// chartLine is a series to draw as a line, or as a shaded band between two
+// series when lower is set.
+type chartLine struct {
+	label  string
+	color  string
+	points []MetricPoint
+	lower  []MetricPoint
+}
+
+type chartTick struct {
+	pos   float64
+	label string
+}
+
+// chartLayout places everything common to both renderers.
+type chartLayout struct {
+	width, height            int
+	left, top, right, bottom float64
+	start, end               time.Time
+	lo, hi                   float64
+	lines                    []chartLine
+	yTicks, xTicks           []chartTick
+	legend                   []chartLine
+	title                    string
+	thresholds               []GraphThreshold
+	markers                  []chartMarker
+}
+
+// chartMarker is a marker with where its label is drawn: on which row, so
+// that labels of markers close together do not overlap, and from labelX,
+// which is left of the line when there is no room to its right.
+type chartMarker struct {
+	GraphMarker
+	row    int
+	labelX float64
+}
+
+// seriesGroup strips the statistic off a series' label, so that the
+// minimum, average and maximum of the same metric can be found together.
+func seriesGroup(s MetricSeries) string {
+	return strings.TrimSuffix(s.Label, " "+s.Stat)
+}
+
+// chartLines turns the series into lines, drawing each Minimum and Maximum
+// pair of the same metric as a single band.
+func chartLines(series []MetricSeries) []chartLine {
+	byGroup := make(map[string]map[string]int)
+	for i, s := range series {
+		g := seriesGroup(s)
+		if byGroup[g] == nil {
+			byGroup[g] = make(map[string]int)
+		}
+		byGroup[g][s.Stat] = i
+	}
+
+	var res []chartLine
+	banded := make(map[int]bool)
+	for i, s := range series {
+		if banded[i] {
+			continue
+		}
+		color := chartColors[len(res)%len(chartColors)]
+		stats := byGroup[seriesGroup(s)]
+		lo, hasMin := stats["Minimum"]
+		hi, hasMax := stats["Maximum"]
+		if hasMin && hasMax && (i == lo || i == hi) {
+			banded[lo], banded[hi] = true, true
+			res = append(res, chartLine{
+				label:  seriesGroup(s) + " Minimum-Maximum",
+				color:  color,
+				points: series[hi].Points,
+				lower:  series[lo].Points,
+			})
+			continue
+		}
+		res = append(res, chartLine{
+			label:  s.Label,
+			color:  color,
+			points: s.Points,
+		})
+	}
+	return res
+}
+
+// niceStep rounds a tick step up to 1, 2 or 5 times a power of ten.
+func niceStep(span float64, ticks int) float64 {
+	raw := span / float64(ticks)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*mag {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
+
+func formatTick(v, step float64) string {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	return fmt.Sprintf("%.*f", decimals, v)
+}
+
+func newChartLayout(chart Chart) chartLayout {
+	l := chartLayout{
+		width:      chart.Width,
+		height:     chart.Height,
+		lines:      chartLines(chart.Series),
+		title:      chart.Title,
+		thresholds: chart.Thresholds,
+	}
+	if l.width <= 0 {
+		l.width = defaultChartWidth
+	}
+	if l.height <= 0 {
+		l.height = defaultChartHeight
+	}
+	l.legend = l.lines
+
+	_, _, lo, hi, ok := chartBounds(chart.Series)
+	if !ok {
+		lo, hi = 0, 1
+	}
+	start, end, ok := chartWindow(chart)
+	if !ok {
+		start, end = time.Now().Add(-time.Hour), time.Now()
+	}
+	if end.Equal(start) {
+		start, end = start.Add(-time.Minute), end.Add(time.Minute)
+	}
+	for _, t := range chart.Thresholds {
+		lo, hi = math.Min(lo, t.Value), math.Max(hi, t.Value)
+	}
+	if chart.YMin != nil {
+		lo = *chart.YMin
+	}
+	if chart.YMax != nil {
+		hi = *chart.YMax
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+	step := niceStep(hi-lo, 5)
+	if chart.YMin == nil {
+		lo = math.Floor(lo/step) * step
+	}
+	if chart.YMax == nil {
+		hi = math.Ceil(hi/step) * step
+	}
+	l.start, l.end, l.lo, l.hi = start, end, lo, hi
+
+	// Count the ticks up front rather than stepping v up to hi, since adding
+	// a step far below the spacing of floats around lo leaves v unchanged.
+	labelWidth := 0
+	first := math.Ceil(lo/step) * step
+	n := int(math.Floor((hi-first)/step + 1e-9))
+	for i := 0; i <= n; i++ {
+		v := first + float64(i)*step
+		label := formatTick(v, step)
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+		l.yTicks = append(l.yTicks, chartTick{pos: v, label: label})
+	}
+
+	l.left = float64(chartPadding + labelWidth*chartCharWidth + chartPadding)
+	l.right = float64(l.width - chartPadding - chartCharWidth*len(chartTimeFormat)/2)
+	l.top = float64(chartPadding)
+	if l.title != "" {
+		l.top += chartCharHeight + chartPadding
+	}
+	// Room below the plot for the time labels and one legend row per line
+	l.bottom = float64(l.height - chartPadding - (chartCharHeight+2)*(len(l.legend)+1) - chartPadding)
+	if l.bottom < l.top+chartCharHeight {
+		l.bottom = l.top + chartCharHeight
+	}
+
+	var rowEnds []float64
+	for _, m := range chart.Markers {
+		if m.Time.Before(start) || m.Time.After(end) {
+			continue
+		}
+		width := float64(len(m.Label) * chartCharWidth)
+		labelX := l.x(m.Time) + 3
+		if labelX+width > float64(l.width-chartPadding) {
+			labelX = l.x(m.Time) - 3 - width
+		}
+		row := 0
+		for row < len(rowEnds) && rowEnds[row] > labelX {
+			row++
+		}
+		if row == len(rowEnds) {
+			rowEnds = append(rowEnds, 0)
+		}
+		rowEnds[row] = labelX + width + chartCharWidth
+		l.markers = append(l.markers, chartMarker{GraphMarker: m, row: row, labelX: labelX})
+	}
+
+	numX := int((l.right - l.left) / float64(chartCharWidth*(len(chartTimeFormat)+4)))
+	if numX < 2 {
+		numX = 2
+	}
+	for i := 0; i < numX; i++ {
+		t := start.Add(time.Duration(float64(end.Sub(start)) * float64(i) / float64(numX-1)))
+		l.xTicks = append(l.xTicks, chartTick{
+			pos:   float64(t.UnixNano()),
+			label: t.Local().Format(chartTimeFormat),
+		})
+	}
+	return l
+}
+
+func (l chartLayout) x(t time.Time) float64 {
+	return l.left + (l.right-l.left)*float64(t.Sub(l.start))/float64(l.end.Sub(l.start))
+}
+
+func (l chartLayout) y(v float64) float64 {
+	return l.bottom - (l.bottom-l.top)*(v-l.lo)/(l.hi-l.lo)
+}
+
+// clampY keeps values outside a fixed axis on the edge of the plot.
+func (l chartLayout) clampY(v float64) float64 {
+	return math.Max(l.top, math.Min(l.bottom, l.y(v)))
+}
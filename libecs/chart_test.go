@@ -0,0 +1,59 @@
+package libecs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestWriteChartLargeValues(t *testing.T) {
+	at := func(min int) time.Time {
+		return time.Date(2024, 5, 1, 12, min, 0, 0, time.UTC)
+	}
+	series := func(values ...float64) []libecs.MetricSeries {
+		s := libecs.MetricSeries{Label: "web RequestCount Sum", Stat: "Sum"}
+		for i, v := range values {
+			s.Points = append(s.Points, libecs.MetricPoint{Time: at(i), Value: v})
+		}
+		return []libecs.MetricSeries{s}
+	}
+	// Around 5e15 floats are 0.5 or 1 apart, below the tick step of a flat
+	// or nearly flat series.
+	charts := map[string]libecs.Chart{
+		"flat":        {Series: series(5e15, 5e15, 5e15)},
+		"nearly flat": {Series: series(5e15, 5e15+2, 5e15+1)},
+	}
+	renderers := map[string]func(io.Writer, libecs.Chart) error{
+		"svg":   libecs.WriteChartSVG,
+		"png":   libecs.WriteChartPNG,
+		"ascii": libecs.WriteChartASCII,
+	}
+	for chartName, chart := range charts {
+		for name, render := range renderers {
+			chart, render := chart, render
+			t.Run(chartName+"/"+name, func(t *testing.T) {
+				chart.Title = "requests"
+				chart.Width, chart.Height = 400, 200
+				done := make(chan error, 1)
+				var out bytes.Buffer
+				go func() {
+					done <- render(&out, chart)
+				}()
+				select {
+				case err := <-done:
+					if err != nil {
+						t.Fatal(err)
+					}
+				case <-time.After(10 * time.Second):
+					t.Fatal("rendering did not finish")
+				}
+				if out.Len() == 0 {
+					t.Errorf("rendered nothing")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package libecs
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// chartFont is a 5x7 bitmap font for PNG chart text, one byte per row with
+// the leftmost pixel in bit 4. Lower case letters are drawn in upper case,
+// and characters without a glyph as '?'.
+var chartFont = map[rune][7]byte{
+	'0': {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1': {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3': {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4': {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5': {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6': {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9': {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	'A': {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'B': {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C': {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D': {0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c},
+	'E': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G': {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H': {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I': {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M': {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P': {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q': {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R': {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S': {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T': {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X': {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x0a, 0x04, 0x04, 0x04, 0x04},
+	'Z': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	',': {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	':': {0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00},
+	'-': {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'_': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+	'/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'%': {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'(': {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')': {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'+': {0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00},
+	'=': {0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00},
+	'<': {0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02},
+	'>': {0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08},
+	'?': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+}
+
+// pngCanvas draws onto an RGBA image, blending translucent colors.
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func parseColor(s string) color.NRGBA {
+	if len(s) == 7 && s[0] == '#' {
+		if v, err := strconv.ParseUint(s[1:], 16, 32); err == nil {
+			return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+		}
+	}
+	return color.NRGBA{A: 0xff}
+}
+
+func (c pngCanvas) set(x, y int, col color.NRGBA) {
+	if !(image.Point{X: x, Y: y}).In(c.img.Bounds()) {
+		return
+	}
+	src := image.NewUniform(col)
+	draw.Draw(c.img, image.Rect(x, y, x+1, y+1), src, image.Point{}, draw.Over)
+}
+
+func (c pngCanvas) fillRect(r image.Rectangle, col color.NRGBA) {
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// line draws a line with Bresenham's algorithm. Dashed lines skip every
+// other run of dash pixels.
+func (c pngCanvas) line(x0, y0, x1, y1 float64, col color.NRGBA, width int, dash int) {
+	ix0, iy0 := int(math.Round(x0)), int(math.Round(y0))
+	ix1, iy1 := int(math.Round(x1)), int(math.Round(y1))
+	dx, dy := abs(ix1-ix0), -abs(iy1-iy0)
+	sx, sy := 1, 1
+	if ix0 > ix1 {
+		sx = -1
+	}
+	if iy0 > iy1 {
+		sy = -1
+	}
+	e := dx + dy
+	for n := 0; ; n++ {
+		if dash == 0 || (n/dash)%2 == 0 {
+			for w := 0; w < width; w++ {
+				if dx >= -dy {
+					c.set(ix0, iy0+w, col)
+				} else {
+					c.set(ix0+w, iy0, col)
+				}
+			}
+		}
+		if ix0 == ix1 && iy0 == iy1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			ix0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			iy0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func textWidth(s string) int {
+	return len([]rune(s)) * chartCharWidth
+}
+
+// text draws s with its top left corner at x, y.
+func (c pngCanvas) text(x, y int, s string, col color.NRGBA) {
+	for _, r := range s {
+		glyph, ok := chartFont[unicode.ToUpper(r)]
+		if !ok && r != ' ' {
+			glyph = chartFont['?']
+		}
+		for row, bits := range glyph {
+			for col5 := 0; col5 < 5; col5++ {
+				if bits&(0x10>>uint(col5)) != 0 {
+					c.set(x+col5, y+row, col)
+				}
+			}
+		}
+		x += chartCharWidth
+	}
+}
+
+// WriteChartPNG draws the chart as a PNG image.
+func WriteChartPNG(out io.Writer, chart Chart) error {
+	l := newChartLayout(chart)
+	c := pngCanvas{img: image.NewRGBA(image.Rect(0, 0, l.width, l.height))}
+	c.fillRect(c.img.Bounds(), color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	grid := parseColor("#e0e0e0")
+	ink := parseColor("#444444")
+
+	if l.title != "" {
+		c.text(chartPadding, chartPadding, l.title, parseColor("#222222"))
+	}
+	for _, t := range l.yTicks {
+		y := l.y(t.pos)
+		c.line(l.left, y, l.right, y, grid, 1, 0)
+		c.text(int(l.left)-chartPadding-textWidth(t.label), int(y)-3, t.label, ink)
+	}
+	for i, t := range l.xTicks {
+		x := int(l.x(time.Unix(0, int64(t.pos))))
+		switch i {
+		case 0:
+		case len(l.xTicks) - 1:
+			x -= textWidth(t.label)
+		default:
+			x -= textWidth(t.label) / 2
+		}
+		c.text(x, int(l.bottom)+chartPadding, t.label, ink)
+	}
+
+	for _, line := range l.lines {
+		col := parseColor(line.color)
+		if line.lower != nil {
+			// Shade each column between the interpolated bounds
+			band := col
+			band.A = 0x40
+			for x := int(l.left); x <= int(l.right); x++ {
+				t := l.start.Add(time.Duration(float64(l.end.Sub(l.start)) * (float64(x) - l.left) /
+					(l.right - l.left)))
+				hi, okHi := interpolate(line.points, t)
+				lo, okLo := interpolate(line.lower, t)
+				if !okHi || !okLo {
+					continue
+				}
+				c.fillRect(image.Rect(x, int(l.clampY(hi)), x+1, int(l.clampY(lo))+1), band)
+			}
+			continue
+		}
+		for i := 1; i < len(line.points); i++ {
+			p, q := line.points[i-1], line.points[i]
+			c.line(l.x(p.Time), l.clampY(p.Value), l.x(q.Time), l.clampY(q.Value), col, 2, 0)
+		}
+		if len(line.points) == 1 {
+			p := line.points[0]
+			c.fillRect(image.Rect(int(l.x(p.Time))-1, int(l.clampY(p.Value))-1, int(l.x(p.Time))+2,
+				int(l.clampY(p.Value))+2), col)
+		}
+	}
+
+	for _, t := range l.thresholds {
+		colorName := t.Color
+		if colorName == "" {
+			colorName = "#d62728"
+		}
+		col := parseColor(colorName)
+		y := l.clampY(t.Value)
+		c.line(l.left, y, l.right, y, col, 1, 5)
+		if t.Label != "" {
+			c.text(int(l.right)-textWidth(t.Label), int(y)-chartCharHeight, t.Label, col)
+		}
+	}
+
+	for _, m := range l.markers {
+		colorName := m.Color
+		if colorName == "" {
+			colorName = defaultMarkerColor
+		}
+		col := parseColor(colorName)
+		x := l.x(m.Time)
+		c.line(x, l.top, x, l.bottom, col, 1, 3)
+		if m.Label != "" {
+			c.text(int(m.labelX), int(l.top)+2+m.row*(chartCharHeight+2), m.Label, col)
+		}
+	}
+
+	border := parseColor("#888888")
+	c.line(l.left, l.top, l.right, l.top, border, 1, 0)
+	c.line(l.left, l.bottom, l.right, l.bottom, border, 1, 0)
+	c.line(l.left, l.top, l.left, l.bottom, border, 1, 0)
+	c.line(l.right, l.top, l.right, l.bottom, border, 1, 0)
+	for i, line := range l.legend {
+		y := int(l.bottom) + chartPadding + (i+1)*(chartCharHeight+2)
+		c.fillRect(image.Rect(int(l.left), y, int(l.left)+12, y+7), parseColor(line.color))
+		c.text(int(l.left)+18, y, line.label, parseColor("#222222"))
+	}
+	return png.Encode(out, c.img)
+}
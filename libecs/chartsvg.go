@@ -0,0 +1,122 @@
+package libecs
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+func svgEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// svgPath traces the points as an SVG path, starting with a move and
+// continuing with lines.
+func svgPath(l chartLayout, points []MetricPoint, b *strings.Builder, move bool) {
+	for _, p := range points {
+		cmd := "L"
+		if move {
+			cmd, move = "M", false
+		}
+		fmt.Fprintf(b, "%s%.1f,%.1f ", cmd, l.x(p.Time), l.clampY(p.Value))
+	}
+}
+
+// WriteChartSVG draws the chart as an SVG document.
+func WriteChartSVG(out io.Writer, chart Chart) error {
+	l := newChartLayout(chart)
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="sans-serif" font-size="11">`+"\n", l.width, l.height, l.width, l.height)
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	if l.title != "" {
+		fmt.Fprintf(w, `<text x="%d" y="%d" font-size="13" font-weight="bold">%s</text>`+"\n",
+			chartPadding, chartPadding+chartCharHeight, svgEscape(l.title))
+	}
+
+	for _, t := range l.yTicks {
+		y := l.y(t.pos)
+		fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n",
+			l.left, y, l.right, y)
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="end" fill="#444444">%s</text>`+"\n",
+			l.left-chartPadding, y+4, svgEscape(t.label))
+	}
+	for i, t := range l.xTicks {
+		anchor := "middle"
+		switch i {
+		case 0:
+			anchor = "start"
+		case len(l.xTicks) - 1:
+			anchor = "end"
+		}
+		x := l.x(time.Unix(0, int64(t.pos)))
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="#444444">%s</text>`+"\n",
+			x, l.bottom+chartPadding+chartCharHeight, anchor, svgEscape(t.label))
+	}
+
+	for _, line := range l.lines {
+		if len(line.points) == 0 {
+			continue
+		}
+		var b strings.Builder
+		if line.lower != nil {
+			svgPath(l, line.points, &b, true)
+			reversed := make([]MetricPoint, len(line.lower))
+			for i, p := range line.lower {
+				reversed[len(line.lower)-1-i] = p
+			}
+			svgPath(l, reversed, &b, false)
+			fmt.Fprintf(w, `<path d="%sZ" fill="%s" fill-opacity="0.25" stroke="none"/>`+"\n",
+				b.String(), line.color)
+			continue
+		}
+		svgPath(l, line.points, &b, true)
+		fmt.Fprintf(w, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n",
+			strings.TrimSpace(b.String()), line.color)
+	}
+
+	for _, t := range l.thresholds {
+		color := t.Color
+		if color == "" {
+			color = "#d62728"
+		}
+		y := l.clampY(t.Value)
+		fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="6,4"/>`+"\n",
+			l.left, y, l.right, y, svgEscape(color))
+		if t.Label != "" {
+			fmt.Fprintf(w, `<text x="%.1f" y="%.1f" text-anchor="end" fill="%s">%s</text>`+"\n",
+				l.right, y-3, svgEscape(color), svgEscape(t.Label))
+		}
+	}
+
+	for _, m := range l.markers {
+		color := m.Color
+		if color == "" {
+			color = defaultMarkerColor
+		}
+		x := l.x(m.Time)
+		fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="3,3"/>`+"\n",
+			x, l.top, x, l.bottom, svgEscape(color))
+		if m.Label != "" {
+			fmt.Fprintf(w, `<text x="%.1f" y="%.1f" fill="%s" font-size="9">%s</text>`+"\n",
+				m.labelX, l.top+float64((m.row+1)*(chartCharHeight+2)), svgEscape(color), svgEscape(m.Label))
+		}
+	}
+
+	fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="#888888"/>`+"\n",
+		l.left, l.top, l.right-l.left, l.bottom-l.top)
+	for i, line := range l.legend {
+		y := l.bottom + chartPadding + float64((i+2)*(chartCharHeight+2))
+		fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="12" height="8" fill="%s"/>`+"\n",
+			l.left, y-8, line.color)
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" fill="#222222">%s</text>`+"\n",
+			l.left+18, y, svgEscape(line.label))
+	}
+	fmt.Fprintf(w, "</svg>\n")
+	return w.Flush()
+}
@@ -0,0 +1,85 @@
+package libecs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// Maximum number of clusters accepted by a single DescribeClusters call.
+const describeClustersBatchSize = 100
+
+type Cluster struct {
+	Name                string
+	Arn                 string
+	Status              string
+	RegisteredInstances int
+	RunningTasks        int
+	PendingTasks        int
+	ActiveServices      int
+	CapacityProviders   []string
+}
+
+func newCluster(c *ecs.Cluster) Cluster {
+	return Cluster{
+		Name:                aws.StringValue(c.ClusterName),
+		Arn:                 aws.StringValue(c.ClusterArn),
+		Status:              aws.StringValue(c.Status),
+		RegisteredInstances: int(aws.Int64Value(c.RegisteredContainerInstancesCount)),
+		RunningTasks:        int(aws.Int64Value(c.RunningTasksCount)),
+		PendingTasks:        int(aws.Int64Value(c.PendingTasksCount)),
+		ActiveServices:      int(aws.Int64Value(c.ActiveServicesCount)),
+		CapacityProviders:   aws.StringValueSlice(c.CapacityProviders),
+	}
+}
+
+// ListClusters returns every cluster in the configured region, regardless of
+// ECSConfig.Cluster.
+func (e *ECS) ListClusters() ([]Cluster, error) {
+	return e.ListClustersWithContext(context.Background())
+}
+
+func (e *ECS) ListClustersWithContext(ctx context.Context) ([]Cluster, error) {
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	var arns []string
+	err := e.ecs.ListClustersPagesWithContext(cctx, &ecs.ListClustersInput{
+		MaxResults: aws.Int64(100),
+	}, func(page *ecs.ListClustersOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(page.ClusterArns)...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.DescribeClustersWithContext(ctx, arns)
+}
+
+// DescribeClusters returns the named clusters, which may be given by name or
+// ARN. Clusters that do not exist are skipped.
+func (e *ECS) DescribeClusters(names []string) ([]Cluster, error) {
+	return e.DescribeClustersWithContext(context.Background(), names)
+}
+
+func (e *ECS) DescribeClustersWithContext(ctx context.Context, names []string) ([]Cluster, error) {
+	var res []Cluster
+	for batchIndex := 0; batchIndex < len(names); batchIndex += describeClustersBatchSize {
+		lim := batchIndex + describeClustersBatchSize
+		if lim >= len(names) {
+			lim = len(names)
+		}
+		cctx, cancel := e.callContext(ctx)
+		resp, err := e.ecs.DescribeClustersWithContext(cctx, &ecs.DescribeClustersInput{
+			Clusters: aws.StringSlice(names[batchIndex:lim]),
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range resp.Clusters {
+			res = append(res, newCluster(c))
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package libecs_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mmaxim/ecstools/libecs"
+	"github.com/mmaxim/ecstools/libecs/ecsfake"
+)
+
+func TestListClusters(t *testing.T) {
+	// ListClusters ignores the configured cluster.
+	e, _ := newECS(t, libecs.ECSConfig{Cluster: "staging"})
+	clusters, err := e.ListClusters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(clusters))
+	}
+	prod := clusters[0]
+	if prod.Name != "prod" || prod.Arn != prodCluster || prod.Status != "ACTIVE" {
+		t.Errorf("cluster = %+v, want prod", prod)
+	}
+	if prod.RegisteredInstances != 2 {
+		t.Errorf("prod has %d instances, want 2", prod.RegisteredInstances)
+	}
+}
+
+func TestDescribeClusters(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		names []string
+		want  int
+	}{
+		{nil, 0},
+		{[]string{"prod"}, 1},
+		{[]string{prodCluster}, 1},
+		{[]string{"prod", "missing"}, 1},
+		{[]string{"missing"}, 0},
+	}
+	for _, c := range cases {
+		clusters, err := e.DescribeClusters(c.names)
+		if err != nil {
+			t.Errorf("DescribeClusters(%v): %s", c.names, err)
+			continue
+		}
+		if len(clusters) != c.want {
+			t.Errorf("DescribeClusters(%v) returned %d clusters, want %d", c.names, len(clusters), c.want)
+		}
+	}
+}
+
+// TestListClustersBatching lists more clusters than fit in one
+// DescribeClusters call, with ListClusters paging one cluster at a time.
+func TestListClustersBatching(t *testing.T) {
+	const numClusters = 150
+	fixture := loadFixture(t)
+	for i := 0; i < numClusters; i++ {
+		name := fmt.Sprintf("cluster%03d", i)
+		fixture.Clusters = append(fixture.Clusters, &ecs.Cluster{
+			ClusterArn:  aws.String("arn:aws:ecs:us-east-1:123456789012:cluster/" + name),
+			ClusterName: aws.String(name),
+			Status:      aws.String("ACTIVE"),
+		})
+	}
+	b := ecsfake.New(fixture)
+	b.PageSize = 7
+	e := b.NewECS(libecs.ECSConfig{Cluster: "prod", Region: fixtureRegion})
+	clusters, err := e.ListClusters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != numClusters+1 {
+		t.Errorf("got %d clusters, want %d", len(clusters), numClusters+1)
+	}
+	if calls := b.Calls("DescribeClusters"); calls != 2 {
+		t.Errorf("DescribeClusters calls = %d, want 2", calls)
+	}
+}
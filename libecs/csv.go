@@ -0,0 +1,98 @@
+package libecs
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteMetricSeriesCSV writes the series side by side, one row per
+// timestamp and one column per series after the time column. Cells are
+// empty where a series has no datapoint.
+func WriteMetricSeriesCSV(out io.Writer, series []MetricSeries) error {
+	w := csv.NewWriter(out)
+	header := []string{"time"}
+	for _, s := range series {
+		header = append(header, s.Label)
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	values := make(map[time.Time][]string)
+	var times []time.Time
+	for i, s := range series {
+		for _, p := range s.Points {
+			t := p.Time.UTC()
+			row, ok := values[t]
+			if !ok {
+				row = make([]string, len(series))
+				values[t] = row
+				times = append(times, t)
+			}
+			row[i] = strconv.FormatFloat(p.Value, 'f', -1, 64)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	for _, t := range times {
+		if err := w.Write(append([]string{t.Format(time.RFC3339)}, values[t]...)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReadMetricSeriesCSV reads series back from WriteMetricSeriesCSV output, so
+// recorded data can be charted later. Each series' statistic is taken from
+// the last word of its label.
+func ReadMetricSeriesCSV(in io.Reader) ([]MetricSeries, error) {
+	r := csv.NewReader(in)
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < 2 || header[0] != "time" {
+		return nil, fmt.Errorf("not a metric series CSV: expected a time column followed by series")
+	}
+	series := make([]MetricSeries, len(header)-1)
+	for i, label := range header[1:] {
+		series[i].Label = label
+		if idx := strings.LastIndex(label, " "); idx >= 0 && validateStat(label[idx+1:]) == nil {
+			series[i].Stat = label[idx+1:]
+		}
+	}
+
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time: %s", row[0])
+		}
+		for i, cell := range row[1:] {
+			if cell == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s at %s: %s", series[i].Label, row[0], cell)
+			}
+			series[i].Points = append(series[i].Points, MetricPoint{Time: t, Value: v})
+		}
+	}
+	for _, s := range series {
+		points := s.Points
+		sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	}
+	return series, nil
+}
@@ -0,0 +1,99 @@
+package libecs_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestWriteMetricSeriesCSV(t *testing.T) {
+	at := func(min int) time.Time {
+		return time.Date(2024, 5, 1, 12, min, 0, 0, time.UTC)
+	}
+	series := []libecs.MetricSeries{
+		{Label: "web CPUUtilization Average", Stat: "Average", Points: []libecs.MetricPoint{
+			{Time: at(2), Value: 0.125},
+			{Time: at(0), Value: 12.5},
+			{Time: at(1), Value: 13},
+		}},
+		// Missing datapoints leave empty cells, and labels needing quoting
+		// are quoted.
+		{Label: "web, requests p99", Stat: "p99", Points: []libecs.MetricPoint{
+			{Time: at(1).In(time.FixedZone("EST", -5*3600)), Value: 250},
+		}},
+		{Label: "no statistic here", Points: []libecs.MetricPoint{
+			{Time: at(3), Value: 1e9},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := libecs.WriteMetricSeriesCSV(&out, series); err != nil {
+		t.Fatal(err)
+	}
+	want := `time,web CPUUtilization Average,"web, requests p99",no statistic here
+2024-05-01T12:00:00Z,12.5,,
+2024-05-01T12:01:00Z,13,250,
+2024-05-01T12:02:00Z,0.125,,
+2024-05-01T12:03:00Z,,,1000000000
+`
+	if got := out.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetricSeriesCSVRoundTrip(t *testing.T) {
+	at := func(min int) time.Time {
+		return time.Date(2024, 5, 1, 12, min, 0, 0, time.UTC)
+	}
+	series := []libecs.MetricSeries{
+		{Label: "web CPUUtilization Average", Stat: "Average", Points: []libecs.MetricPoint{
+			{Time: at(0), Value: 12.5},
+			{Time: at(1), Value: 13},
+			{Time: at(2), Value: 0.125},
+		}},
+		// Missing datapoints leave empty cells, and labels needing quoting
+		// survive.
+		{Label: "web, requests p99", Stat: "p99", Points: []libecs.MetricPoint{
+			{Time: at(1), Value: 250},
+		}},
+		{Label: "no statistic here", Points: []libecs.MetricPoint{
+			{Time: at(2), Value: -1},
+			{Time: at(3), Value: 1e9},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := libecs.WriteMetricSeriesCSV(&out, series); err != nil {
+		t.Fatal(err)
+	}
+	got, err := libecs.ReadMetricSeriesCSV(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, series) {
+		t.Errorf("round trip = %+v, want %+v", got, series)
+	}
+}
+
+func TestReadMetricSeriesCSVErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":       "",
+		"no series":   "time\n",
+		"not a time":  "when,a\n2024-05-01T12:00:00Z,1\n",
+		"bad time":    "time,a\nnoon,1\n",
+		"bad value":   "time,a\n2024-05-01T12:00:00Z,lots\n",
+		"short row":   "time,a,b\n2024-05-01T12:00:00Z,1\n",
+		"unquoted \"": "time,a\"b\n",
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := libecs.ReadMetricSeriesCSV(strings.NewReader(in)); err == nil {
+				t.Errorf("ReadMetricSeriesCSV(%q) succeeded, want an error", in)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const deploymentStatusPrimary = "PRIMARY"
+
+type Deployment struct {
+	ID string
+	// Status is PRIMARY for the newest deployment, ACTIVE for older ones
+	// still running tasks, and INACTIVE once drained.
+	Status         string
+	TaskDefinition string
+	DesiredCount   int
+	RunningCount   int
+	PendingCount   int
+	FailedTasks    int
+	// RolloutState is IN_PROGRESS, COMPLETED or FAILED.
+	RolloutState       string
+	RolloutStateReason string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func newDeployment(d *ecs.Deployment) Deployment {
+	return Deployment{
+		ID:                 aws.StringValue(d.Id),
+		Status:             aws.StringValue(d.Status),
+		TaskDefinition:     aws.StringValue(d.TaskDefinition),
+		DesiredCount:       int(aws.Int64Value(d.DesiredCount)),
+		RunningCount:       int(aws.Int64Value(d.RunningCount)),
+		PendingCount:       int(aws.Int64Value(d.PendingCount)),
+		FailedTasks:        int(aws.Int64Value(d.FailedTasks)),
+		RolloutState:       aws.StringValue(d.RolloutState),
+		RolloutStateReason: aws.StringValue(d.RolloutStateReason),
+		CreatedAt:          aws.TimeValue(d.CreatedAt).Local(),
+		UpdatedAt:          aws.TimeValue(d.UpdatedAt).Local(),
+	}
+}
+
+// PrimaryDeployment returns the service's newest deployment, if it has one.
+func (s Service) PrimaryDeployment() (Deployment, bool) {
+	for _, d := range s.Deployments {
+		if d.Status == deploymentStatusPrimary {
+			return d, true
+		}
+	}
+	return Deployment{}, false
+}
+
+// DeploymentInProgress reports whether the service is rolling out a new
+// deployment, either because its primary deployment says so or because more
+// than one task definition is live.
+func (s Service) DeploymentInProgress() bool {
+	if primary, ok := s.PrimaryDeployment(); ok &&
+		primary.RolloutState == ecs.DeploymentRolloutStateInProgress {
+		return true
+	}
+	return len(s.Deployments) > 1
+}
+
+// DeployState summarizes the service's deployments as steady, deploying or
+// FAILED, or n/a if it has none.
+func (s Service) DeployState() string {
+	switch {
+	case len(s.Deployments) == 0:
+		return "n/a"
+	case s.DeploymentFailed():
+		return "FAILED"
+	case s.DeploymentInProgress():
+		return fmt.Sprintf("deploying (%d live)", len(s.Deployments))
+	default:
+		return "steady"
+	}
+}
+
+// DeploymentFailed reports whether the service's latest rollout failed.
+func (s Service) DeploymentFailed() bool {
+	primary, ok := s.PrimaryDeployment()
+	return ok && primary.RolloutState == ecs.DeploymentRolloutStateFailed
+}
+
+var (
+	deploymentStartedEvent  = regexp.MustCompile(`has started a deployment: (\S+?)\.?$`)
+	deploymentFinishedEvent = regexp.MustCompile(`\(deployment (\S+)\) deployment (completed|failed)`)
+)
+
+// deploymentMarkerColors are keyed by what happened to the deployment.
+var deploymentMarkerColors = map[string]string{
+	"started":   "#7f7f7f",
+	"completed": "#2ca02c",
+	"failed":    "#d62728",
+}
+
+// ServiceDeploymentMarkers returns graph markers for each deployment of the
+// service that started, completed or failed in the window, found from its
+// current deployments and its event history. The zero window is the last 24
+// hours, as for GraphOptions.
+func (e *ECS) ServiceDeploymentMarkers(service string, window TimeRange) ([]GraphMarker, error) {
+	return e.ServiceDeploymentMarkersWithContext(context.Background(), service, window)
+}
+
+func (e *ECS) ServiceDeploymentMarkersWithContext(ctx context.Context, service string,
+	window TimeRange) ([]GraphMarker, error) {
+	svc, err := e.describeService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	start, end := GraphOptions{Range: window}.timeRange().bounds(time.Now())
+	return deploymentMarkers(svc, start, end), nil
+}
+
+func deploymentMarkers(svc *ecs.Service, start, end time.Time) []GraphMarker {
+	type key struct{ id, what string }
+	seen := make(map[key]time.Time)
+	// Events record when rollouts finished more precisely than UpdatedAt,
+	// which also moves as a deployment scales, so they come first.
+	for _, ev := range svc.Events {
+		msg := aws.StringValue(ev.Message)
+		if m := deploymentStartedEvent.FindStringSubmatch(msg); m != nil {
+			seen[key{m[1], "started"}] = aws.TimeValue(ev.CreatedAt)
+		} else if m := deploymentFinishedEvent.FindStringSubmatch(msg); m != nil {
+			seen[key{m[1], m[2]}] = aws.TimeValue(ev.CreatedAt)
+		}
+	}
+	taskDefs := make(map[string]string)
+	for _, d := range svc.Deployments {
+		id := aws.StringValue(d.Id)
+		taskDef := aws.StringValue(d.TaskDefinition)
+		taskDefs[id] = taskDef[strings.LastIndex(taskDef, "/")+1:]
+		if _, ok := seen[key{id, "started"}]; !ok {
+			seen[key{id, "started"}] = aws.TimeValue(d.CreatedAt)
+		}
+		var what string
+		switch aws.StringValue(d.RolloutState) {
+		case ecs.DeploymentRolloutStateCompleted:
+			what = "completed"
+		case ecs.DeploymentRolloutStateFailed:
+			what = "failed"
+		default:
+			continue
+		}
+		_, completed := seen[key{id, "completed"}]
+		_, failed := seen[key{id, "failed"}]
+		if !completed && !failed {
+			seen[key{id, what}] = aws.TimeValue(d.UpdatedAt)
+		}
+	}
+
+	var res []GraphMarker
+	for k, t := range seen {
+		if t.Before(start) || t.After(end) {
+			continue
+		}
+		name := "deployment"
+		if taskDef := taskDefs[k.id]; taskDef != "" {
+			name = taskDef
+		}
+		res = append(res, GraphMarker{
+			Time:  t.Local(),
+			Label: name + " " + k.what,
+			Color: deploymentMarkerColors[k.what],
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Time.Equal(res[j].Time) {
+			return res[i].Label < res[j].Label
+		}
+		return res[i].Time.Before(res[j].Time)
+	})
+	return res
+}
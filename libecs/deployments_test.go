@@ -0,0 +1,118 @@
+package libecs_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestDeployState(t *testing.T) {
+	primary := func(state string) libecs.Deployment {
+		return libecs.Deployment{Status: "PRIMARY", RolloutState: state}
+	}
+	old := libecs.Deployment{Status: "ACTIVE", RolloutState: "COMPLETED"}
+	cases := []struct {
+		name        string
+		deployments []libecs.Deployment
+		want        string
+		inProgress  bool
+		failed      bool
+	}{
+		{name: "none", want: "n/a"},
+		{name: "completed", deployments: []libecs.Deployment{primary("COMPLETED")}, want: "steady"},
+		{name: "in progress", deployments: []libecs.Deployment{primary("IN_PROGRESS")},
+			want: "deploying (1 live)", inProgress: true},
+		{name: "two live", deployments: []libecs.Deployment{primary("COMPLETED"), old},
+			want: "deploying (2 live)", inProgress: true},
+		{name: "failed", deployments: []libecs.Deployment{primary("FAILED"), old},
+			want: "FAILED", inProgress: true, failed: true},
+		// Services without the deployment circuit breaker report no rollout
+		// state at all.
+		{name: "no rollout state", deployments: []libecs.Deployment{primary("")}, want: "steady"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := libecs.Service{Deployments: c.deployments}
+			if got := svc.DeployState(); got != c.want {
+				t.Errorf("DeployState() = %s, want %s", got, c.want)
+			}
+			if got := svc.DeploymentInProgress(); got != c.inProgress {
+				t.Errorf("DeploymentInProgress() = %v, want %v", got, c.inProgress)
+			}
+			if got := svc.DeploymentFailed(); got != c.failed {
+				t.Errorf("DeploymentFailed() = %v, want %v", got, c.failed)
+			}
+		})
+	}
+}
+
+func TestListServicesDeployments(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"web": "deploying (2 live)", "worker": "steady", "api": "steady"}
+	for _, s := range services {
+		if got := s.DeployState(); got != want[s.Name] {
+			t.Errorf("%s deploy state = %s, want %s", s.Name, got, want[s.Name])
+		}
+	}
+	primary, ok := services[0].PrimaryDeployment()
+	if !ok || primary.ID != "ecs-svc/1111111111111111111" || primary.DesiredCount != 2 {
+		t.Errorf("web primary deployment = %+v, %v", primary, ok)
+	}
+}
+
+func TestGetServiceGraphMarkers(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{})
+	window := libecs.Between(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC))
+	if _, err := e.GetServiceGraph("web", "CPUUtilization", libecs.GraphOptions{Range: window}); err != nil {
+		t.Fatal(err)
+	}
+	widgets := b.Widgets()
+	if len(widgets) != 1 {
+		t.Fatalf("rendered %d widgets, want 1", len(widgets))
+	}
+	var widget struct {
+		Annotations struct {
+			Vertical []struct {
+				Value time.Time
+				Label string
+				Color string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(widgets[0]), &widget); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2026-10-01T11:58:00Z web:12 started",
+		"2026-10-01T12:05:00Z web:12 completed",
+		"2026-10-03T09:00:00Z web:13 started",
+	}
+	var got []string
+	for _, a := range widget.Annotations.Vertical {
+		got = append(got, a.Value.UTC().Format(time.RFC3339)+" "+a.Label)
+		if a.Color == "" {
+			t.Errorf("annotation %q has no color", a.Label)
+		}
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("vertical annotations = %q, want %q", got, want)
+	}
+
+	// Markers outside the window are left off the graph.
+	window = libecs.Between(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+	if _, err := e.GetServiceGraph("web", "CPUUtilization", libecs.GraphOptions{Range: window}); err != nil {
+		t.Fatal(err)
+	}
+	if widgets := b.Widgets(); strings.Contains(widgets[len(widgets)-1], `"vertical"`) {
+		t.Errorf("widget has annotations outside its window: %s", widgets[len(widgets)-1])
+	}
+}
@@ -1,25 +1,57 @@
 package libecs
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+)
+
+// Maximum number of resources accepted by a single Describe* call.
+const (
+	describeServicesBatchSize = 10
+	describeTasksBatchSize    = 100
 )
 
 type ECSConfig struct {
 	Cluster string
 	Region  string
+	// Concurrency caps the number of in-flight AWS requests while fanning out
+	// per service lookups and metric batches. Zero means defaultConcurrency.
+	Concurrency int
+	// CallTimeout bounds each AWS API call, with a paginated listing counting
+	// as one call. Zero means calls are only bounded by the caller's context.
+	CallTimeout time.Duration
+	// PollInterval is how often to check on a service while waiting for it to
+	// change. Zero means defaultPollInterval.
+	PollInterval time.Duration
+	// MinDesiredCount and MaxDesiredCount bound the desired counts accepted
+	// by ScaleService. A zero MaxDesiredCount means no maximum.
+	MinDesiredCount int
+	MaxDesiredCount int
+	// TaskMetrics selects where per-task CPU and memory come from. Empty
+	// means TaskMetricsAuto.
+	TaskMetrics TaskMetricsSource
+	// Alarms makes ListServices look up the firing CloudWatch alarms of each
+	// service. It is off by default since it needs cloudwatch:DescribeAlarms.
+	Alarms bool
+	// AlarmNamePrefix limits alarm lookups to alarms whose names start with
+	// it, so that accounts with many alarms are not scanned in full.
+	AlarmNamePrefix string
 }
 
 type ECS struct {
-	ecs        *ecs.ECS
-	cloudwatch *cloudwatch.CloudWatch
+	ecs        ecsiface.ECSAPI
+	cloudwatch cloudwatchiface.CloudWatchAPI
 	config     ECSConfig
 }
 
@@ -29,8 +61,39 @@ type Task struct {
 	Status          string
 	DesiredStatus   string
 	TaskDefinition  string
+	HealthStatus    string
+	StopCode        string
+	StoppedReason   string
 	CreatedAt       time.Time
+	StartedAt       time.Time
+	StoppedAt       time.Time
+	Containers      []Container
 	InstanceMetrics *InstanceMetrics
+	// LaunchType is EC2, FARGATE or EXTERNAL. Tasks placed by a capacity
+	// provider also name it in CapacityProvider.
+	LaunchType       string
+	PlatformVersion  string
+	CapacityProvider string
+	AvailabilityZone string
+	// PrivateIP is the address of the task's elastic network interface, for
+	// tasks using awsvpc networking.
+	PrivateIP   string
+	TaskMetrics *TaskMetrics
+}
+
+type Container struct {
+	Name         string
+	Image        string
+	ImageDigest  string
+	Status       string
+	HealthStatus string
+	// ExitCode is nil until the container has exited.
+	ExitCode *int
+	Reason   string
+	// CPU is in CPU units and memory in MiB. Zero means no reservation.
+	CPU               int
+	Memory            int
+	MemoryReservation int
 }
 
 type Service struct {
@@ -39,8 +102,20 @@ type Service struct {
 	RunningCount   int
 	PendingCount   int
 	TaskDefinition string
+	Deployments    []Deployment
 	Tasks          []Task
 	Metrics        ServiceMetrics
+	// LaunchType is empty for services that use a capacity provider strategy
+	// instead, in which case CapacityProviders lists the providers.
+	LaunchType        string
+	PlatformVersion   string
+	CapacityProviders []string
+	// TargetGroups are the ARNs of the load balancer target groups the
+	// service registers its tasks with.
+	TargetGroups []string
+	// Alarms holds the firing alarms on the service. It is nil unless alarms
+	// were looked up, see ECSConfig.Alarms.
+	Alarms []Alarm
 }
 
 type InstanceMetrics struct {
@@ -54,18 +129,22 @@ type ServiceMetrics struct {
 }
 
 func New(config ECSConfig) (*ECS, error) {
-	ret := &ECS{
-		config: config,
-	}
-
 	sess, err := session.NewSession(&aws.Config{Region: aws.String(config.Region)})
 	if err != nil {
 		return nil, err
 	}
-	ret.ecs = ecs.New(sess)
-	ret.cloudwatch = cloudwatch.New(sess)
+	return NewWithClients(config, ecs.New(sess), cloudwatch.New(sess)), nil
+}
 
-	return ret, nil
+// NewWithClients builds an ECS on top of caller supplied API clients, such as
+// the fakes in libecs/ecsfake, instead of ones backed by a real AWS session.
+func NewWithClients(config ECSConfig, ecsClient ecsiface.ECSAPI,
+	cwClient cloudwatchiface.CloudWatchAPI) *ECS {
+	return &ECS{
+		ecs:        ecsClient,
+		cloudwatch: cwClient,
+		config:     config,
+	}
 }
 
 func (e *ECS) cluster() string {
@@ -76,266 +155,333 @@ func (e *ECS) region() string {
 	return e.config.Region
 }
 
-func (e *ECS) getInstanceMetrics(instance string) (InstanceMetrics, error) {
-	resp, err := e.ecs.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
-		Cluster:            aws.String(e.cluster()),
-		ContainerInstances: []*string{aws.String(instance)},
-	})
-	if err != nil {
-		return InstanceMetrics{}, err
-	}
-	id := resp.ContainerInstances[0].Ec2InstanceId
-	dim := cloudwatch.Dimension{
-		Name:  aws.String("InstanceId"),
-		Value: id,
-	}
-	period := int64(60)
-	start := time.Now().Add(-2 * time.Minute)
-	end := time.Now()
-	cpuResp, err := e.cloudwatch.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
-		Dimensions: []*cloudwatch.Dimension{&dim},
-		MetricName: aws.String("CPUUtilization"),
-		Statistics: []*string{aws.String("Average")},
-		Period:     &period,
-		StartTime:  &start,
-		EndTime:    &end,
-		Namespace:  aws.String("AWS/EC2"),
-	})
-	if err != nil {
-		return InstanceMetrics{}, err
+// callContext derives the context for a single AWS API call from ctx,
+// applying CallTimeout if one is configured.
+func (e *ECS) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e.config.CallTimeout > 0 {
+		return context.WithTimeout(ctx, e.config.CallTimeout)
 	}
+	return context.WithCancel(ctx)
+}
+
+// TaskStatus selects tasks by their desired status. ECS keeps stopped tasks
+// around for at least an hour after they stop.
+type TaskStatus string
+
+const (
+	TaskStatusRunning TaskStatus = "running"
+	TaskStatusStopped TaskStatus = "stopped"
+	TaskStatusAll     TaskStatus = "all"
+)
 
-	var cpu float64
-	if len(cpuResp.Datapoints) > 0 {
-		cpu = *cpuResp.Datapoints[0].Average
-	} else {
-		cpu = 0
+func ParseTaskStatus(s string) (TaskStatus, error) {
+	switch status := TaskStatus(strings.ToLower(s)); status {
+	case TaskStatusRunning, TaskStatusStopped, TaskStatusAll:
+		return status, nil
 	}
-	return InstanceMetrics{
-		CPU: cpu,
-		ID:  aws.StringValue(id),
-	}, nil
+	return "", fmt.Errorf("unknown task status: %s", s)
 }
 
+func (s TaskStatus) desiredStatuses() []string {
+	switch s {
+	case TaskStatusStopped:
+		return []string{ecs.DesiredStatusStopped}
+	case TaskStatusAll:
+		return []string{ecs.DesiredStatusRunning, ecs.DesiredStatusStopped}
+	default:
+		return []string{ecs.DesiredStatusRunning}
+	}
+}
+
+func (e *ECS) listTaskArns(ctx context.Context, serviceName string, status TaskStatus) ([]*string, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	var arns []*string
+	for _, desired := range status.desiredStatuses() {
+		err := e.ecs.ListTasksPagesWithContext(ctx, &ecs.ListTasksInput{
+			Cluster:       aws.String(e.cluster()),
+			ServiceName:   aws.String(serviceName),
+			DesiredStatus: aws.String(desired),
+			MaxResults:    aws.Int64(100),
+		}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+			arns = append(arns, page.TaskArns...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return arns, nil
+}
+
+// ListTasks returns the service's running tasks.
 func (e *ECS) ListTasks(serviceName string) ([]Task, error) {
-	resp, err := e.ecs.ListTasks(&ecs.ListTasksInput{
-		Cluster:     aws.String(e.cluster()),
-		ServiceName: aws.String(serviceName),
-	})
+	return e.ListTasksWithContext(context.Background(), serviceName)
+}
+
+func (e *ECS) ListTasksWithContext(ctx context.Context, serviceName string) ([]Task, error) {
+	return e.ListTasksWithStatusWithContext(ctx, serviceName, TaskStatusRunning)
+}
+
+// ListTasksWithStatus returns the service's tasks with the given desired
+// status.
+func (e *ECS) ListTasksWithStatus(serviceName string, status TaskStatus) ([]Task, error) {
+	return e.ListTasksWithStatusWithContext(context.Background(), serviceName, status)
+}
+
+func (e *ECS) ListTasksWithStatusWithContext(ctx context.Context, serviceName string,
+	status TaskStatus) ([]Task, error) {
+	arns, err := e.listTaskArns(ctx, serviceName, status)
 	if err != nil {
 		return nil, err
 	}
-	if len(resp.TaskArns) == 0 {
-		return nil, nil
-	}
-
-	respt, err := e.ecs.DescribeTasks(&ecs.DescribeTasksInput{
-		Cluster: aws.String(e.cluster()),
-		Tasks:   resp.TaskArns,
-	})
+	res, err := e.describeTasks(ctx, arns)
 	if err != nil {
 		return nil, err
 	}
+	tasks := make([]*Task, len(res))
+	for i := range res {
+		tasks[i] = &res[i]
+	}
+	if err := e.fillInstanceMetrics(ctx, tasks); err != nil {
+		return nil, err
+	}
+	if err := e.fillTaskMetrics(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
 
-	var res []Task
-	for _, t := range respt.Tasks {
-		var im *InstanceMetrics
-		if len(aws.StringValue(t.ContainerInstanceArn)) > 0 {
-			im = new(InstanceMetrics)
-			if *im, err = e.getInstanceMetrics(aws.StringValue(t.ContainerInstanceArn)); err != nil {
-				return res, err
-			}
-		}
-		res = append(res, Task{
-			Arn:             aws.StringValue(t.TaskArn),
-			InstanceArn:     aws.StringValue(t.ContainerInstanceArn),
-			Status:          aws.StringValue(t.LastStatus),
-			DesiredStatus:   aws.StringValue(t.DesiredStatus),
-			TaskDefinition:  aws.StringValue(t.TaskDefinitionArn),
-			CreatedAt:       aws.TimeValue(t.CreatedAt).Local(),
-			InstanceMetrics: im,
-		})
+// atoi parses the numeric strings ECS uses for CPU and memory sizes, treating
+// missing or malformed values as zero.
+func atoi(s *string) int {
+	n, _ := strconv.Atoi(aws.StringValue(s))
+	return n
+}
+
+func newContainer(c *ecs.Container) Container {
+	res := Container{
+		Name:              aws.StringValue(c.Name),
+		Image:             aws.StringValue(c.Image),
+		ImageDigest:       aws.StringValue(c.ImageDigest),
+		Status:            aws.StringValue(c.LastStatus),
+		HealthStatus:      aws.StringValue(c.HealthStatus),
+		Reason:            aws.StringValue(c.Reason),
+		CPU:               atoi(c.Cpu),
+		Memory:            atoi(c.Memory),
+		MemoryReservation: atoi(c.MemoryReservation),
+	}
+	if c.ExitCode != nil {
+		code := int(*c.ExitCode)
+		res.ExitCode = &code
 	}
+	return res
+}
 
-	return res, nil
+func newTask(t *ecs.Task) Task {
+	res := Task{
+		Arn:            aws.StringValue(t.TaskArn),
+		InstanceArn:    aws.StringValue(t.ContainerInstanceArn),
+		Status:         aws.StringValue(t.LastStatus),
+		DesiredStatus:  aws.StringValue(t.DesiredStatus),
+		TaskDefinition: aws.StringValue(t.TaskDefinitionArn),
+		HealthStatus:   aws.StringValue(t.HealthStatus),
+		StopCode:       aws.StringValue(t.StopCode),
+		StoppedReason:  aws.StringValue(t.StoppedReason),
+		CreatedAt:      aws.TimeValue(t.CreatedAt).Local(),
+		StartedAt:      aws.TimeValue(t.StartedAt).Local(),
+		StoppedAt:      aws.TimeValue(t.StoppedAt).Local(),
+
+		LaunchType:       aws.StringValue(t.LaunchType),
+		PlatformVersion:  aws.StringValue(t.PlatformVersion),
+		CapacityProvider: aws.StringValue(t.CapacityProviderName),
+		AvailabilityZone: aws.StringValue(t.AvailabilityZone),
+		PrivateIP:        taskPrivateIP(t),
+	}
+	for _, c := range t.Containers {
+		res.Containers = append(res.Containers, newContainer(c))
+	}
+	return res
 }
 
-func (e *ECS) getServiceMetrics(svcname string) (ServiceMetrics, error) {
-	dims := []*cloudwatch.Dimension{
-		&cloudwatch.Dimension{
-			Name:  aws.String("ClusterName"),
-			Value: aws.String(e.cluster()),
-		},
-		&cloudwatch.Dimension{
-			Name:  aws.String("ServiceName"),
-			Value: aws.String(svcname),
-		},
-	}
-	period := int64(60)
-	start := time.Now().Add(-2 * time.Minute)
-	end := time.Now()
-	cpuResp, err := e.cloudwatch.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
-		Dimensions: dims,
-		MetricName: aws.String("CPUUtilization"),
-		Statistics: []*string{aws.String("Average")},
-		Period:     &period,
-		StartTime:  &start,
-		EndTime:    &end,
-		Namespace:  aws.String("AWS/ECS"),
-	})
-	if err != nil {
-		return ServiceMetrics{}, err
+// taskPrivateIP finds the private address of the task's elastic network
+// interface, falling back to the addresses reported on its containers.
+func taskPrivateIP(t *ecs.Task) string {
+	for _, a := range t.Attachments {
+		if aws.StringValue(a.Type) != "ElasticNetworkInterface" {
+			continue
+		}
+		for _, d := range a.Details {
+			if aws.StringValue(d.Name) == "privateIPv4Address" {
+				return aws.StringValue(d.Value)
+			}
+		}
 	}
+	for _, c := range t.Containers {
+		for _, ni := range c.NetworkInterfaces {
+			if ip := aws.StringValue(ni.PrivateIpv4Address); ip != "" {
+				return ip
+			}
+		}
+	}
+	return ""
+}
 
-	memResp, err := e.cloudwatch.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
-		Dimensions: dims,
-		MetricName: aws.String("MemoryUtilization"),
-		Statistics: []*string{aws.String("Average")},
-		Period:     &period,
-		StartTime:  &start,
-		EndTime:    &end,
-		Namespace:  aws.String("AWS/ECS"),
-	})
-	if err != nil {
-		return ServiceMetrics{}, err
-	}
-
-	var cpu, mem float64
-	if len(cpuResp.Datapoints) > 0 {
-		cpu = *cpuResp.Datapoints[0].Average
-	} else {
-		cpu = 0
-	}
-	if len(memResp.Datapoints) > 0 {
-		mem = *memResp.Datapoints[0].Average
-	} else {
-		mem = 0
-	}
-	return ServiceMetrics{
-		CPU:    cpu,
-		Memory: mem,
-	}, nil
-}
-
-func (e *ECS) getServiceMetricGraph(svcname, metric string, duration time.Duration) (io.Reader, error) {
-	metrics := fmt.Sprintf(`
-		{
-			"title": "%s %s",
-			"period": 1,
-			"yAxis":{
-				"left":{
-				   "min":0,
-				   "max":100
-				}
-			 },
-			"metrics": [
-				[
-					"AWS/ECS", 
-					"%s", 
-					"ClusterName", 
-					"%s",
-					"ServiceName",
-					"%s",
-					{
-						"id": "m1",
-						"stat": "Average"
-					}
-				],
-				[
-					".", 
-					".", 
-					".", 
-					".",
-					".",
-					".",
-					{
-						"id": "m2",
-						"stat": "Maximum"
-					}
-				],
-				[
-					".", 
-					".", 
-					".", 
-					".",
-					".",
-					".",
-					{
-						"id": "m3",
-						"stat": "Minimum"
-					}
-				]
-			]
+func (e *ECS) describeTasks(ctx context.Context, arns []*string) ([]Task, error) {
+	var res []Task
+	for batchIndex := 0; batchIndex < len(arns); batchIndex += describeTasksBatchSize {
+		lim := batchIndex + describeTasksBatchSize
+		if lim >= len(arns) {
+			lim = len(arns)
+		}
+		cctx, cancel := e.callContext(ctx)
+		respt, err := e.ecs.DescribeTasksWithContext(cctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(e.cluster()),
+			Tasks:   arns[batchIndex:lim],
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range respt.Tasks {
+			res = append(res, newTask(t))
 		}
-	`, svcname, metric, metric, e.cluster(), svcname)
-	res, err := e.cloudwatch.GetMetricWidgetImage(&cloudwatch.GetMetricWidgetImageInput{
-		MetricWidget: aws.String(metrics),
-		OutputFormat: aws.String("png"),
-	})
-	if err != nil {
-		return nil, err
 	}
-	return bytes.NewBuffer(res.MetricWidgetImage), nil
+
+	return res, nil
 }
 
 func (e *ECS) GetServiceCPUGraph(svcname string, duration time.Duration) (io.Reader, error) {
-	return e.getServiceMetricGraph(svcname, "CPUUtilization", duration)
+	return e.GetServiceCPUGraphWithContext(context.Background(), svcname, duration)
+}
+
+func (e *ECS) GetServiceCPUGraphWithContext(ctx context.Context, svcname string,
+	duration time.Duration) (io.Reader, error) {
+	return e.GetServiceGraphWithContext(ctx, svcname, "CPUUtilization", GraphOptions{Range: Last(duration)})
 }
 
 func (e *ECS) GetServiceMemoryGraph(svcname string, duration time.Duration) (io.Reader, error) {
-	return e.getServiceMetricGraph(svcname, "MemoryUtilization", duration)
+	return e.GetServiceMemoryGraphWithContext(context.Background(), svcname, duration)
 }
 
-func (e *ECS) ListServices() ([]Service, error) {
+func (e *ECS) GetServiceMemoryGraphWithContext(ctx context.Context, svcname string,
+	duration time.Duration) (io.Reader, error) {
+	return e.GetServiceGraphWithContext(ctx, svcname, "MemoryUtilization", GraphOptions{Range: Last(duration)})
+}
 
-	// Fetch all service ARNS
+func newService(svc *ecs.Service) Service {
+	s := Service{
+		Name:           aws.StringValue(svc.ServiceName),
+		Arn:            aws.StringValue(svc.ServiceArn),
+		RunningCount:   int(aws.Int64Value(svc.RunningCount)),
+		PendingCount:   int(aws.Int64Value(svc.PendingCount)),
+		TaskDefinition: aws.StringValue(svc.TaskDefinition),
+
+		LaunchType:      aws.StringValue(svc.LaunchType),
+		PlatformVersion: aws.StringValue(svc.PlatformVersion),
+	}
+	for _, cp := range svc.CapacityProviderStrategy {
+		s.CapacityProviders = append(s.CapacityProviders, aws.StringValue(cp.CapacityProvider))
+	}
+	for _, d := range svc.Deployments {
+		s.Deployments = append(s.Deployments, newDeployment(d))
+	}
+	for _, lb := range svc.LoadBalancers {
+		if lb.TargetGroupArn != nil {
+			s.TargetGroups = append(s.TargetGroups, aws.StringValue(lb.TargetGroupArn))
+		}
+	}
+	return s
+}
+
+func (e *ECS) listServiceArns(ctx context.Context) ([]*string, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
 	params := &ecs.ListServicesInput{
 		Cluster:    aws.String(e.cluster()),
 		MaxResults: aws.Int64(100),
 	}
-	resp, err := e.ecs.ListServices(params)
+	var serviceArns []*string
+	err := e.ecs.ListServicesPagesWithContext(ctx, params, func(page *ecs.ListServicesOutput, lastPage bool) bool {
+		serviceArns = append(serviceArns, page.ServiceArns...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return serviceArns, nil
+}
 
-	var res []Service
-	batchSize := 10
-	for batchIndex := 0; batchIndex < len(resp.ServiceArns); batchIndex += batchSize {
-		var arns []*string
-		lim := batchIndex + batchSize
-		if lim >= len(resp.ServiceArns) {
-			lim = len(resp.ServiceArns)
-		}
-		for i := batchIndex; i < lim; i++ {
-			arns = append(arns, resp.ServiceArns[i])
+func (e *ECS) ListServices() ([]Service, error) {
+	return e.ListServicesWithContext(context.Background())
+}
+
+func (e *ECS) ListServicesWithContext(ctx context.Context) ([]Service, error) {
+
+	// Fetch all service ARNS
+	serviceArns, err := e.listServiceArns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var described []*ecs.Service
+	for batchIndex := 0; batchIndex < len(serviceArns); batchIndex += describeServicesBatchSize {
+		lim := batchIndex + describeServicesBatchSize
+		if lim >= len(serviceArns) {
+			lim = len(serviceArns)
 		}
+		arns := serviceArns[batchIndex:lim]
 
 		// Fetch service descriptions
-		sresp, err := e.ecs.DescribeServices(&ecs.DescribeServicesInput{
+		cctx, cancel := e.callContext(ctx)
+		sresp, err := e.ecs.DescribeServicesWithContext(cctx, &ecs.DescribeServicesInput{
 			Services: arns,
 			Cluster:  aws.String(e.cluster()),
 		})
+		cancel()
 		if err != nil {
 			return nil, err
 		}
+		described = append(described, sresp.Services...)
+	}
 
-		for _, svc := range sresp.Services {
-			metrics, err := e.getServiceMetrics(aws.StringValue(svc.ServiceName))
-			if err != nil {
-				return nil, err
-			}
-			s := Service{
-				Name:           aws.StringValue(svc.ServiceName),
-				Arn:            aws.StringValue(svc.ServiceArn),
-				RunningCount:   int(aws.Int64Value(svc.RunningCount)),
-				PendingCount:   int(aws.Int64Value(svc.PendingCount)),
-				TaskDefinition: aws.StringValue(svc.TaskDefinition),
-				Metrics:        metrics,
-			}
-			if s.Tasks, err = e.ListTasks(aws.StringValue(svc.ServiceName)); err != nil {
-				return nil, err
-			}
-			res = append(res, s)
+	// Fetch tasks for each service
+	res := make([]Service, len(described))
+	if err := forEach(len(described), e.concurrency(), func(i int) error {
+		svc := described[i]
+		res[i] = newService(svc)
+		taskArns, err := e.listTaskArns(ctx, aws.StringValue(svc.ServiceName), TaskStatusRunning)
+		if err != nil {
+			return err
+		}
+		res[i].Tasks, err = e.describeTasks(ctx, taskArns)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// Fetch metrics for every service and every task's instance in batches
+	svcs := make([]*Service, len(res))
+	var tasks []*Task
+	for i := range res {
+		svcs[i] = &res[i]
+		for j := range res[i].Tasks {
+			tasks = append(tasks, &res[i].Tasks[j])
+		}
+	}
+	if err := e.fillServiceMetrics(ctx, svcs); err != nil {
+		return nil, err
+	}
+	if err := e.fillInstanceMetrics(ctx, tasks); err != nil {
+		return nil, err
+	}
+	if err := e.fillTaskMetrics(ctx, tasks); err != nil {
+		return nil, err
+	}
+	if e.config.Alarms {
+		if err := e.fillServiceAlarms(ctx, svcs); err != nil {
+			return nil, err
 		}
 	}
 
@@ -0,0 +1,278 @@
+package libecs_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mmaxim/ecstools/libecs"
+	"github.com/mmaxim/ecstools/libecs/ecsfake"
+)
+
+const (
+	fixturePath   = "ecsfake/testdata/basic.json"
+	prodCluster   = "arn:aws:ecs:us-east-1:123456789012:cluster/prod"
+	fixtureRegion = "us-east-1"
+)
+
+func loadFixture(t *testing.T) *ecsfake.Fixture {
+	t.Helper()
+	fixture, err := ecsfake.LoadFixture(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fixture
+}
+
+func newECS(t *testing.T, config libecs.ECSConfig) (*libecs.ECS, *ecsfake.Backend) {
+	t.Helper()
+	b := ecsfake.New(loadFixture(t))
+	if config.Cluster == "" {
+		config.Cluster = "prod"
+	}
+	config.Region = fixtureRegion
+	return b.NewECS(config), b
+}
+
+func serviceNames(services []libecs.Service) []string {
+	var names []string
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestListServices(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(serviceNames(services), ","), "web,worker,api"; got != want {
+		t.Fatalf("services = %s, want %s", got, want)
+	}
+	web := services[0]
+	if len(web.Tasks) != 2 {
+		t.Errorf("web has %d tasks, want 2", len(web.Tasks))
+	}
+	if web.Metrics.CPU <= 0 || web.Metrics.Memory <= 0 {
+		t.Errorf("web metrics = %+v, want CPU and memory from the fixture", web.Metrics)
+	}
+	for _, task := range web.Tasks {
+		if task.InstanceMetrics == nil || task.InstanceMetrics.ID == "" {
+			t.Errorf("task %s has no instance metrics", task.Arn)
+		}
+	}
+}
+
+func TestListServicesFargate(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := services[2]
+	if len(api.Tasks) != 1 {
+		t.Fatalf("api has %d tasks, want 1", len(api.Tasks))
+	}
+	task := api.Tasks[0]
+	if task.LaunchType != ecs.LaunchTypeFargate {
+		t.Errorf("api task launch type = %q, want %s", task.LaunchType, ecs.LaunchTypeFargate)
+	}
+	if task.InstanceMetrics != nil {
+		t.Errorf("Fargate task has instance metrics %+v", task.InstanceMetrics)
+	}
+	if task.TaskMetrics == nil || task.TaskMetrics.CPUPercent() <= 0 || task.TaskMetrics.MemoryPercent() <= 0 {
+		t.Errorf("Fargate task metrics = %+v, want Container Insights usage", task.TaskMetrics)
+	}
+	if task.PrivateIP == "" || task.AvailabilityZone == "" {
+		t.Errorf("Fargate task placement = %q in %q, want its ENI address and zone", task.PrivateIP,
+			task.AvailabilityZone)
+	}
+}
+
+func TestListServicesOtherCluster(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{Cluster: "staging"})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Errorf("staging services = %v, want none", serviceNames(services))
+	}
+}
+
+func TestListServicesPaging(t *testing.T) {
+	for _, pageSize := range []int{0, 1, 2} {
+		t.Run(fmt.Sprintf("page size %d", pageSize), func(t *testing.T) {
+			b := ecsfake.New(loadFixture(t))
+			b.PageSize = pageSize
+			e := b.NewECS(libecs.ECSConfig{Cluster: "prod", Region: fixtureRegion})
+			services, err := e.ListServices()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := strings.Join(serviceNames(services), ","), "web,worker,api"; got != want {
+				t.Errorf("services = %s, want %s", got, want)
+			}
+			tasks := make(map[string]int)
+			for _, s := range services {
+				tasks[s.Name] = len(s.Tasks)
+			}
+			if tasks["web"] != 2 || tasks["worker"] != 1 || tasks["api"] != 1 {
+				t.Errorf("running tasks per service = %v", tasks)
+			}
+		})
+	}
+}
+
+// addServices grows the fixture with generated Fargate services, the first
+// of which runs numTasks tasks.
+func addServices(fixture *ecsfake.Fixture, numServices, numTasks int) {
+	for i := 0; i < numServices; i++ {
+		name := fmt.Sprintf("svc%03d", i)
+		fixture.Services = append(fixture.Services, &ecs.Service{
+			ClusterArn:   aws.String(prodCluster),
+			ServiceArn:   aws.String("arn:aws:ecs:us-east-1:123456789012:service/prod/" + name),
+			ServiceName:  aws.String(name),
+			Status:       aws.String("ACTIVE"),
+			LaunchType:   aws.String(ecs.LaunchTypeFargate),
+			DesiredCount: aws.Int64(1),
+		})
+	}
+	for i := 0; i < numTasks; i++ {
+		fixture.Tasks = append(fixture.Tasks, &ecs.Task{
+			ClusterArn:    aws.String(prodCluster),
+			TaskArn:       aws.String(fmt.Sprintf("arn:aws:ecs:us-east-1:123456789012:task/prod/%032x", i)),
+			Group:         aws.String("service:svc000"),
+			LaunchType:    aws.String(ecs.LaunchTypeFargate),
+			LastStatus:    aws.String(ecs.DesiredStatusRunning),
+			DesiredStatus: aws.String(ecs.DesiredStatusRunning),
+		})
+	}
+}
+
+// TestListServicesBatching grows the fixture past the ListServices page size
+// and the DescribeServices, DescribeTasks and GetMetricData batch limits,
+// which the fake enforces.
+func TestListServicesBatching(t *testing.T) {
+	const numServices, numTasks = 260, 150
+	fixture := loadFixture(t)
+	numFixture := len(fixture.Services)
+	addServices(fixture, numServices, numTasks)
+	b := ecsfake.New(fixture)
+	e := b.NewECS(libecs.ECSConfig{Cluster: "prod", Region: fixtureRegion})
+
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != numFixture+numServices {
+		t.Fatalf("got %d services, want %d", len(services), numFixture+numServices)
+	}
+	for _, s := range services {
+		if s.Name == "svc000" && len(s.Tasks) != numTasks {
+			t.Errorf("svc000 has %d tasks, want %d", len(s.Tasks), numTasks)
+		}
+	}
+	if got, want := b.Calls("DescribeServices"), (numFixture+numServices+9)/10; got != want {
+		t.Errorf("DescribeServices calls = %d, want %d", got, want)
+	}
+	if got := b.Calls("DescribeTasks"); got < 2 {
+		t.Errorf("DescribeTasks calls = %d, want the tasks split across at least 2", got)
+	}
+	if got := b.Calls("GetMetricData"); got < 2 {
+		t.Errorf("GetMetricData calls = %d, want the queries split across at least 2", got)
+	}
+	if got := b.Calls("GetMetricStatistics"); got != 0 {
+		t.Errorf("GetMetricStatistics called %d times, want metrics batched", got)
+	}
+
+	tasks, err := e.ListTasks("svc000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != numTasks {
+		t.Errorf("ListTasks returned %d tasks, want %d", len(tasks), numTasks)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.ListServicesWithContext(ctx); err == nil {
+		t.Errorf("ListServicesWithContext succeeded with a canceled context")
+	}
+	if _, err := e.ListTasksWithContext(ctx, "web"); err == nil {
+		t.Errorf("ListTasksWithContext succeeded with a canceled context")
+	}
+	if calls := b.Calls("DescribeServices") + b.Calls("DescribeTasks"); calls != 0 {
+		t.Errorf("made %d describe calls after the context was canceled", calls)
+	}
+}
+
+func TestListTasksDetail(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	tasks, err := e.ListTasks("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	task := tasks[0]
+	if task.HealthStatus != "HEALTHY" || task.StartedAt.IsZero() || !task.StoppedAt.IsZero() {
+		t.Errorf("task = %+v", task)
+	}
+	if len(task.Containers) != 1 {
+		t.Fatalf("got %d containers, want 1", len(task.Containers))
+	}
+	c := task.Containers[0]
+	if c.Name != "web" || c.Status != "RUNNING" || c.ExitCode != nil || c.CPU != 512 ||
+		c.Memory != 1024 || c.MemoryReservation != 512 || !strings.HasPrefix(c.ImageDigest, "sha256:") {
+		t.Errorf("container = %+v", c)
+	}
+}
+
+func TestListTasksWithStatus(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		status libecs.TaskStatus
+		want   []string
+	}{
+		{libecs.TaskStatusRunning, []string{"0a1b2c3d", "1b2c3d4e"}},
+		{libecs.TaskStatusStopped, []string{"3d4e5f60"}},
+		{libecs.TaskStatusAll, []string{"0a1b2c3d", "1b2c3d4e", "3d4e5f60"}},
+	}
+	for _, c := range cases {
+		t.Run(string(c.status), func(t *testing.T) {
+			tasks, err := e.ListTasksWithStatus("web", c.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, task := range tasks {
+				id := task.Arn[strings.LastIndex(task.Arn, "/")+1:]
+				got = append(got, id[:8])
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("tasks = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseTaskStatus(t *testing.T) {
+	for _, s := range []string{"running", "STOPPED", "All"} {
+		if _, err := libecs.ParseTaskStatus(s); err != nil {
+			t.Errorf("ParseTaskStatus(%q): %s", s, err)
+		}
+	}
+	if _, err := libecs.ParseTaskStatus("pending"); err == nil {
+		t.Errorf("ParseTaskStatus(%q) succeeded", "pending")
+	}
+}
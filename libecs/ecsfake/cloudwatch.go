@@ -0,0 +1,331 @@
+package ecsfake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+)
+
+type CloudWatchClient struct {
+	cloudwatchiface.CloudWatchAPI
+
+	backend *Backend
+}
+
+type sample struct {
+	ts    time.Time
+	value float64
+}
+
+func (b *Backend) findMetric(namespace, name string, dims []*cloudwatch.Dimension) *Metric {
+	for i, m := range b.fixture.Metrics {
+		if m.Namespace != namespace || m.MetricName != name || len(m.Dimensions) != len(dims) {
+			continue
+		}
+		match := true
+		for _, d := range dims {
+			if v, ok := m.Dimensions[aws.StringValue(d.Name)]; !ok || v != aws.StringValue(d.Value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return &b.fixture.Metrics[i]
+		}
+	}
+	return nil
+}
+
+// samples stamps the metric values one minute apart, ending now, and returns
+// those inside [start, end).
+func (b *Backend) samples(m *Metric, start, end time.Time) []sample {
+	if m == nil {
+		return nil
+	}
+	now := b.Now().Truncate(time.Minute)
+	var res []sample
+	for i, v := range m.Values {
+		ts := now.Add(-time.Duration(len(m.Values)-1-i) * time.Minute)
+		if !ts.Before(start) && ts.Before(end) {
+			res = append(res, sample{ts: ts, value: v})
+		}
+	}
+	return res
+}
+
+type bucket struct {
+	start  time.Time
+	values []float64
+}
+
+// aggregate groups samples into periods aligned to start, in time order.
+func aggregate(samples []sample, start time.Time, period time.Duration) []bucket {
+	var res []bucket
+	for _, s := range samples {
+		bstart := start.Add(s.ts.Sub(start) / period * period)
+		if len(res) == 0 || !res[len(res)-1].start.Equal(bstart) {
+			res = append(res, bucket{start: bstart})
+		}
+		res[len(res)-1].values = append(res[len(res)-1].values, s.value)
+	}
+	return res
+}
+
+// statistic computes a CloudWatch statistic such as Average or p90 over the
+// values in a bucket.
+func statistic(values []float64, stat string) (float64, error) {
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no values")
+	}
+	var sum float64
+	min, max := values[0], values[0]
+	for _, v := range values {
+		sum += v
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	switch stat {
+	case cloudwatch.StatisticAverage:
+		return sum / float64(len(values)), nil
+	case cloudwatch.StatisticSum:
+		return sum, nil
+	case cloudwatch.StatisticMinimum:
+		return min, nil
+	case cloudwatch.StatisticMaximum:
+		return max, nil
+	case cloudwatch.StatisticSampleCount:
+		return float64(len(values)), nil
+	}
+	if strings.HasPrefix(stat, "p") {
+		pct, err := strconv.ParseFloat(stat[1:], 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return 0, invalidParameter("invalid statistic: %s", stat)
+		}
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		rank := int(math.Ceil(pct/100*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return sorted[rank], nil
+	}
+	return 0, invalidParameter("invalid statistic: %s", stat)
+}
+
+func (c *CloudWatchClient) GetMetricStatistics(input *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("GetMetricStatistics")
+
+	start, end := aws.TimeValue(input.StartTime), aws.TimeValue(input.EndTime)
+	period := time.Duration(aws.Int64Value(input.Period)) * time.Second
+	m := b.findMetric(aws.StringValue(input.Namespace), aws.StringValue(input.MetricName), input.Dimensions)
+	res := &cloudwatch.GetMetricStatisticsOutput{
+		Label: input.MetricName,
+	}
+	// CloudWatch does not order these, so hand them back newest first rather
+	// than in the order callers might assume.
+	buckets := aggregate(b.samples(m, start, end), start, period)
+	for i := len(buckets) - 1; i >= 0; i-- {
+		dp := &cloudwatch.Datapoint{
+			Timestamp: aws.Time(buckets[i].start),
+		}
+		for _, stat := range input.Statistics {
+			v, err := statistic(buckets[i].values, *stat)
+			if err != nil {
+				return nil, err
+			}
+			switch *stat {
+			case cloudwatch.StatisticAverage:
+				dp.Average = aws.Float64(v)
+			case cloudwatch.StatisticSum:
+				dp.Sum = aws.Float64(v)
+			case cloudwatch.StatisticMinimum:
+				dp.Minimum = aws.Float64(v)
+			case cloudwatch.StatisticMaximum:
+				dp.Maximum = aws.Float64(v)
+			case cloudwatch.StatisticSampleCount:
+				dp.SampleCount = aws.Float64(v)
+			}
+		}
+		res.Datapoints = append(res.Datapoints, dp)
+	}
+	return res, nil
+}
+
+func (c *CloudWatchClient) GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("GetMetricData")
+
+	if len(input.MetricDataQueries) > 500 {
+		return nil, invalidParameter("too many metric data queries: %d", len(input.MetricDataQueries))
+	}
+	start, end := aws.TimeValue(input.StartTime), aws.TimeValue(input.EndTime)
+	res := &cloudwatch.GetMetricDataOutput{}
+	for _, q := range input.MetricDataQueries {
+		if q.MetricStat == nil {
+			return nil, invalidParameter("only MetricStat queries are supported: %s", aws.StringValue(q.Id))
+		}
+		stat := q.MetricStat
+		period := time.Duration(aws.Int64Value(stat.Period)) * time.Second
+		m := b.findMetric(aws.StringValue(stat.Metric.Namespace), aws.StringValue(stat.Metric.MetricName),
+			stat.Metric.Dimensions)
+		r := &cloudwatch.MetricDataResult{
+			Id:         q.Id,
+			Label:      q.Label,
+			StatusCode: aws.String(cloudwatch.StatusCodeComplete),
+		}
+		if r.Label == nil {
+			r.Label = stat.Metric.MetricName
+		}
+		for _, bk := range aggregate(b.samples(m, start, end), start, period) {
+			v, err := statistic(bk.values, aws.StringValue(stat.Stat))
+			if err != nil {
+				return nil, err
+			}
+			r.Timestamps = append(r.Timestamps, aws.Time(bk.start))
+			r.Values = append(r.Values, aws.Float64(v))
+		}
+		if aws.StringValue(input.ScanBy) != cloudwatch.ScanByTimestampAscending {
+			for i, j := 0, len(r.Values)-1; i < j; i, j = i+1, j-1 {
+				r.Timestamps[i], r.Timestamps[j] = r.Timestamps[j], r.Timestamps[i]
+				r.Values[i], r.Values[j] = r.Values[j], r.Values[i]
+			}
+		}
+		res.MetricDataResults = append(res.MetricDataResults, r)
+	}
+	return res, nil
+}
+
+func (c *CloudWatchClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput,
+	opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetMetricData(input)
+}
+
+func (c *CloudWatchClient) GetMetricDataPages(input *cloudwatch.GetMetricDataInput,
+	fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+	return c.GetMetricDataPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+// GetMetricDataPagesWithContext answers every query in a single page.
+func (c *CloudWatchClient) GetMetricDataPagesWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput,
+	fn func(*cloudwatch.GetMetricDataOutput, bool) bool, opts ...request.Option) error {
+	page, err := c.GetMetricDataWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	fn(page, true)
+	return nil
+}
+
+func (c *CloudWatchClient) GetMetricWidgetImage(input *cloudwatch.GetMetricWidgetImageInput) (*cloudwatch.GetMetricWidgetImageOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("GetMetricWidgetImage")
+
+	if !json.Valid([]byte(aws.StringValue(input.MetricWidget))) {
+		return nil, invalidParameter("metric widget is not valid JSON")
+	}
+	b.widgets = append(b.widgets, aws.StringValue(input.MetricWidget))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		return nil, err
+	}
+	return &cloudwatch.GetMetricWidgetImageOutput{
+		MetricWidgetImage: buf.Bytes(),
+	}, nil
+}
+
+func (c *CloudWatchClient) GetMetricWidgetImageWithContext(ctx aws.Context,
+	input *cloudwatch.GetMetricWidgetImageInput, opts ...request.Option) (*cloudwatch.GetMetricWidgetImageOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetMetricWidgetImage(input)
+}
+
+// DescribeAlarms supports filtering by alarm name and state. Only metric
+// alarms are kept in fixtures, so composite alarms are never returned.
+func (c *CloudWatchClient) DescribeAlarms(input *cloudwatch.DescribeAlarmsInput) (*cloudwatch.DescribeAlarmsOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeAlarms")
+
+	if input.MaxRecords != nil && (*input.MaxRecords < 1 || *input.MaxRecords > 100) {
+		return nil, invalidParameter("invalid MaxRecords: %d", *input.MaxRecords)
+	}
+	names := make(map[string]bool)
+	for _, name := range input.AlarmNames {
+		names[aws.StringValue(name)] = true
+	}
+	var alarms []*cloudwatch.MetricAlarm
+	for _, a := range b.fixture.Alarms {
+		name := aws.StringValue(a.AlarmName)
+		if len(names) > 0 && !names[name] {
+			continue
+		}
+		if input.AlarmNamePrefix != nil && !strings.HasPrefix(name, *input.AlarmNamePrefix) {
+			continue
+		}
+		if input.StateValue != nil && aws.StringValue(a.StateValue) != *input.StateValue {
+			continue
+		}
+		alarms = append(alarms, a)
+	}
+	start, end, next, err := b.page(len(alarms), input.NextToken, input.MaxRecords, 50)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudwatch.DescribeAlarmsOutput{
+		MetricAlarms: alarms[start:end],
+		NextToken:    next,
+	}, nil
+}
+
+func (c *CloudWatchClient) DescribeAlarmsWithContext(ctx aws.Context, input *cloudwatch.DescribeAlarmsInput,
+	opts ...request.Option) (*cloudwatch.DescribeAlarmsOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeAlarms(input)
+}
+
+func (c *CloudWatchClient) DescribeAlarmsPages(input *cloudwatch.DescribeAlarmsInput,
+	fn func(*cloudwatch.DescribeAlarmsOutput, bool) bool) error {
+	return c.DescribeAlarmsPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *CloudWatchClient) DescribeAlarmsPagesWithContext(ctx aws.Context, input *cloudwatch.DescribeAlarmsInput,
+	fn func(*cloudwatch.DescribeAlarmsOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.DescribeAlarmsWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
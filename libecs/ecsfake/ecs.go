@@ -0,0 +1,543 @@
+package ecsfake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+)
+
+type ECSClient struct {
+	ecsiface.ECSAPI
+
+	backend *Backend
+}
+
+func (c *ECSClient) clusterMatches(arn *string, cluster *string) bool {
+	return arnMatches(arn, clusterRef(cluster))
+}
+
+func (c *ECSClient) ListServices(input *ecs.ListServicesInput) (*ecs.ListServicesOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("ListServices")
+
+	var arns []*string
+	for _, svc := range b.fixture.Services {
+		if c.clusterMatches(svc.ClusterArn, input.Cluster) {
+			arns = append(arns, svc.ServiceArn)
+		}
+	}
+	start, end, next, err := b.page(len(arns), input.NextToken, input.MaxResults, 10)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListServicesOutput{
+		ServiceArns: arns[start:end],
+		NextToken:   next,
+	}, nil
+}
+
+func (c *ECSClient) DescribeServices(input *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeServices")
+
+	if len(input.Services) > 10 {
+		return nil, invalidParameter("too many services: %d", len(input.Services))
+	}
+	res := &ecs.DescribeServicesOutput{}
+	for _, ref := range input.Services {
+		if svc := b.findService(input.Cluster, ref); svc != nil {
+			b.advance(svc)
+			res.Services = append(res.Services, copyService(svc))
+		} else {
+			res.Failures = append(res.Failures, &ecs.Failure{
+				Arn:    ref,
+				Reason: aws.String("MISSING"),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (c *ECSClient) ListTasks(input *ecs.ListTasksInput) (*ecs.ListTasksOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("ListTasks")
+
+	desired := ecs.DesiredStatusRunning
+	if input.DesiredStatus != nil {
+		desired = *input.DesiredStatus
+	}
+	var arns []*string
+	for _, task := range b.fixture.Tasks {
+		if !c.clusterMatches(task.ClusterArn, input.Cluster) {
+			continue
+		}
+		if input.ServiceName != nil && aws.StringValue(task.Group) != "service:"+*input.ServiceName {
+			continue
+		}
+		if input.ContainerInstance != nil && !arnMatches(task.ContainerInstanceArn, *input.ContainerInstance) {
+			continue
+		}
+		if aws.StringValue(task.DesiredStatus) != desired {
+			continue
+		}
+		arns = append(arns, task.TaskArn)
+	}
+	start, end, next, err := b.page(len(arns), input.NextToken, input.MaxResults, 100)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListTasksOutput{
+		TaskArns:  arns[start:end],
+		NextToken: next,
+	}, nil
+}
+
+func (c *ECSClient) DescribeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeTasks")
+
+	if len(input.Tasks) > 100 {
+		return nil, invalidParameter("too many tasks: %d", len(input.Tasks))
+	}
+	res := &ecs.DescribeTasksOutput{}
+	for _, ref := range input.Tasks {
+		found := false
+		for _, task := range b.fixture.Tasks {
+			if c.clusterMatches(task.ClusterArn, input.Cluster) && arnMatches(task.TaskArn, *ref) {
+				res.Tasks = append(res.Tasks, copyTask(task))
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.Failures = append(res.Failures, &ecs.Failure{
+				Arn:    ref,
+				Reason: aws.String("MISSING"),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (c *ECSClient) DescribeContainerInstances(input *ecs.DescribeContainerInstancesInput) (*ecs.DescribeContainerInstancesOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeContainerInstances")
+
+	if len(input.ContainerInstances) > 100 {
+		return nil, invalidParameter("too many container instances: %d", len(input.ContainerInstances))
+	}
+	res := &ecs.DescribeContainerInstancesOutput{}
+	for _, ref := range input.ContainerInstances {
+		found := false
+		for _, inst := range b.fixture.ContainerInstances {
+			if arnMatches(inst.ContainerInstanceArn, *ref) {
+				res.ContainerInstances = append(res.ContainerInstances, inst)
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.Failures = append(res.Failures, &ecs.Failure{
+				Arn:    ref,
+				Reason: aws.String("MISSING"),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (c *ECSClient) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput,
+	opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListServices(input)
+}
+
+func (c *ECSClient) ListServicesPages(input *ecs.ListServicesInput,
+	fn func(*ecs.ListServicesOutput, bool) bool) error {
+	return c.ListServicesPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *ECSClient) ListServicesPagesWithContext(ctx aws.Context, input *ecs.ListServicesInput,
+	fn func(*ecs.ListServicesOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.ListServicesWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+func (c *ECSClient) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput,
+	opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeServices(input)
+}
+
+func (c *ECSClient) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput,
+	opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListTasks(input)
+}
+
+func (c *ECSClient) ListTasksPages(input *ecs.ListTasksInput,
+	fn func(*ecs.ListTasksOutput, bool) bool) error {
+	return c.ListTasksPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *ECSClient) ListTasksPagesWithContext(ctx aws.Context, input *ecs.ListTasksInput,
+	fn func(*ecs.ListTasksOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.ListTasksWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+func (c *ECSClient) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput,
+	opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeTasks(input)
+}
+
+func (c *ECSClient) DescribeContainerInstancesWithContext(ctx aws.Context,
+	input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeContainerInstances(input)
+}
+
+func (c *ECSClient) ListClusters(input *ecs.ListClustersInput) (*ecs.ListClustersOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("ListClusters")
+
+	var arns []*string
+	for _, cluster := range b.fixture.Clusters {
+		arns = append(arns, cluster.ClusterArn)
+	}
+	start, end, next, err := b.page(len(arns), input.NextToken, input.MaxResults, 100)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListClustersOutput{
+		ClusterArns: arns[start:end],
+		NextToken:   next,
+	}, nil
+}
+
+func (c *ECSClient) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput,
+	opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListClusters(input)
+}
+
+func (c *ECSClient) ListClustersPages(input *ecs.ListClustersInput,
+	fn func(*ecs.ListClustersOutput, bool) bool) error {
+	return c.ListClustersPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *ECSClient) ListClustersPagesWithContext(ctx aws.Context, input *ecs.ListClustersInput,
+	fn func(*ecs.ListClustersOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.ListClustersWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+func (c *ECSClient) DescribeClusters(input *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeClusters")
+
+	if len(input.Clusters) > 100 {
+		return nil, invalidParameter("too many clusters: %d", len(input.Clusters))
+	}
+	refs := input.Clusters
+	if len(refs) == 0 {
+		refs = []*string{aws.String("default")}
+	}
+	res := &ecs.DescribeClustersOutput{}
+	for _, ref := range refs {
+		found := false
+		for _, cluster := range b.fixture.Clusters {
+			if arnMatches(cluster.ClusterArn, *ref) {
+				res.Clusters = append(res.Clusters, cluster)
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.Failures = append(res.Failures, &ecs.Failure{
+				Arn:    ref,
+				Reason: aws.String("MISSING"),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (c *ECSClient) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput,
+	opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeClusters(input)
+}
+
+func (c *ECSClient) ListContainerInstances(input *ecs.ListContainerInstancesInput) (*ecs.ListContainerInstancesOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("ListContainerInstances")
+
+	var arns []*string
+	for _, inst := range b.fixture.ContainerInstances {
+		if !inCluster(inst.ContainerInstanceArn, input.Cluster) {
+			continue
+		}
+		if input.Status != nil && aws.StringValue(inst.Status) != *input.Status {
+			continue
+		}
+		arns = append(arns, inst.ContainerInstanceArn)
+	}
+	start, end, next, err := b.page(len(arns), input.NextToken, input.MaxResults, 100)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListContainerInstancesOutput{
+		ContainerInstanceArns: arns[start:end],
+		NextToken:             next,
+	}, nil
+}
+
+func (c *ECSClient) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput,
+	opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListContainerInstances(input)
+}
+
+func (c *ECSClient) ListContainerInstancesPages(input *ecs.ListContainerInstancesInput,
+	fn func(*ecs.ListContainerInstancesOutput, bool) bool) error {
+	return c.ListContainerInstancesPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *ECSClient) ListContainerInstancesPagesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput,
+	fn func(*ecs.ListContainerInstancesOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.ListContainerInstancesWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+// findTaskDefinition resolves an ARN, family:revision, or bare family (the
+// latest active revision) to a fixture task definition.
+func (b *Backend) findTaskDefinition(ref string) *ecs.TaskDefinition {
+	var latest *ecs.TaskDefinition
+	for _, td := range b.fixture.TaskDefinitions {
+		name := fmt.Sprintf("%s:%d", aws.StringValue(td.Family), aws.Int64Value(td.Revision))
+		if arnMatches(td.TaskDefinitionArn, ref) || name == ref {
+			return td
+		}
+		if aws.StringValue(td.Family) == ref && aws.StringValue(td.Status) == ecs.TaskDefinitionStatusActive &&
+			(latest == nil || aws.Int64Value(td.Revision) > aws.Int64Value(latest.Revision)) {
+			latest = td
+		}
+	}
+	return latest
+}
+
+func (c *ECSClient) DescribeTaskDefinition(input *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("DescribeTaskDefinition")
+
+	td := b.findTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if td == nil {
+		return nil, awserr.New(ecs.ErrCodeClientException, "Unable to describe task definition.", nil)
+	}
+	return &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: td,
+	}, nil
+}
+
+func (c *ECSClient) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput,
+	opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeTaskDefinition(input)
+}
+
+func (c *ECSClient) ListTaskDefinitions(input *ecs.ListTaskDefinitionsInput) (*ecs.ListTaskDefinitionsOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("ListTaskDefinitions")
+
+	status := ecs.TaskDefinitionStatusActive
+	if input.Status != nil {
+		status = *input.Status
+	}
+	var tds []*ecs.TaskDefinition
+	for _, td := range b.fixture.TaskDefinitions {
+		if input.FamilyPrefix != nil && !strings.HasPrefix(aws.StringValue(td.Family), *input.FamilyPrefix) {
+			continue
+		}
+		if aws.StringValue(td.Status) != status {
+			continue
+		}
+		tds = append(tds, td)
+	}
+	desc := aws.StringValue(input.Sort) == ecs.SortOrderDesc
+	sort.SliceStable(tds, func(i, j int) bool {
+		fi, fj := aws.StringValue(tds[i].Family), aws.StringValue(tds[j].Family)
+		if fi != fj {
+			return (fi < fj) != desc
+		}
+		return (aws.Int64Value(tds[i].Revision) < aws.Int64Value(tds[j].Revision)) != desc
+	})
+	var arns []*string
+	for _, td := range tds {
+		arns = append(arns, td.TaskDefinitionArn)
+	}
+	start, end, next, err := b.page(len(arns), input.NextToken, input.MaxResults, 100)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListTaskDefinitionsOutput{
+		TaskDefinitionArns: arns[start:end],
+		NextToken:          next,
+	}, nil
+}
+
+func (c *ECSClient) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput,
+	opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListTaskDefinitions(input)
+}
+
+func (c *ECSClient) ListTaskDefinitionsPages(input *ecs.ListTaskDefinitionsInput,
+	fn func(*ecs.ListTaskDefinitionsOutput, bool) bool) error {
+	return c.ListTaskDefinitionsPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+func (c *ECSClient) ListTaskDefinitionsPagesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput,
+	fn func(*ecs.ListTaskDefinitionsOutput, bool) bool, opts ...request.Option) error {
+	in := *input
+	for {
+		page, err := c.ListTaskDefinitionsWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if !fn(page, page.NextToken == nil) || page.NextToken == nil {
+			return nil
+		}
+		in.NextToken = page.NextToken
+	}
+}
+
+func copyTask(task *ecs.Task) *ecs.Task {
+	res := &ecs.Task{}
+	awsutil.Copy(res, task)
+	return res
+}
+
+// StopTask stops the task immediately, as if all of its containers exited
+// on the stop signal.
+func (c *ECSClient) StopTask(input *ecs.StopTaskInput) (*ecs.StopTaskOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("StopTask")
+
+	for _, task := range b.fixture.Tasks {
+		if !c.clusterMatches(task.ClusterArn, input.Cluster) || !arnMatches(task.TaskArn, aws.StringValue(input.Task)) {
+			continue
+		}
+		if aws.StringValue(task.LastStatus) != ecs.DesiredStatusStopped {
+			now := b.Now()
+			task.LastStatus = aws.String(ecs.DesiredStatusStopped)
+			task.DesiredStatus = aws.String(ecs.DesiredStatusStopped)
+			task.StopCode = aws.String(ecs.TaskStopCodeUserInitiated)
+			task.StoppedReason = input.Reason
+			task.StoppingAt = aws.Time(now)
+			task.StoppedAt = aws.Time(now)
+			for _, container := range task.Containers {
+				container.LastStatus = aws.String(ecs.DesiredStatusStopped)
+				container.ExitCode = aws.Int64(143)
+			}
+		}
+		return &ecs.StopTaskOutput{
+			Task: copyTask(task),
+		}, nil
+	}
+	return nil, invalidParameter("The referenced task was not found.")
+}
+
+func (c *ECSClient) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput,
+	opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.StopTask(input)
+}
@@ -0,0 +1,172 @@
+// Package ecsfake provides in-memory implementations of the ECS and CloudWatch
+// API clients used by libecs, seeded from fixtures, so libecs can be exercised
+// without talking to AWS.
+//
+// Only the operations libecs calls are implemented; calling anything else
+// panics on the nil embedded interface.
+package ecsfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+// Fixture is the state served by a Backend. It is plain SDK types so it can
+// be written by hand as JSON, using the SDK field names as keys.
+type Fixture struct {
+	Clusters           []*ecs.Cluster
+	Services           []*ecs.Service
+	Tasks              []*ecs.Task
+	ContainerInstances []*ecs.ContainerInstance
+	TaskDefinitions    []*ecs.TaskDefinition
+	Metrics            []Metric
+	Alarms             []*cloudwatch.MetricAlarm
+}
+
+// Metric is a CloudWatch metric with one sample per minute. Values are newest
+// last, and the final value is stamped with the minute the request is served
+// in, so fixtures never go stale.
+type Metric struct {
+	Namespace  string
+	MetricName string
+	Dimensions map[string]string
+	Values     []float64
+}
+
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("error parsing fixture %s: %s", path, err)
+	}
+	return &fixture, nil
+}
+
+type Backend struct {
+	sync.Mutex
+
+	// PageSize caps the page size of List* calls, to exercise pagination with
+	// small fixtures. Zero means use the API defaults.
+	PageSize int
+	// Now is the clock used to stamp metric samples, events and deployments.
+	Now func() time.Time
+	// FailDeployments makes deployments started through UpdateService fail
+	// instead of completing.
+	FailDeployments bool
+
+	fixture       *Fixture
+	calls         map[string]int
+	widgets       []string
+	rolling       map[string]bool
+	deploymentSeq int
+	eventSeq      int
+}
+
+func New(fixture *Fixture) *Backend {
+	return &Backend{
+		Now:     time.Now,
+		fixture: fixture,
+		calls:   make(map[string]int),
+		rolling: make(map[string]bool),
+	}
+}
+
+// NewECS returns a libecs.ECS wired to this backend.
+func (b *Backend) NewECS(config libecs.ECSConfig) *libecs.ECS {
+	return libecs.NewWithClients(config, &ECSClient{backend: b}, &CloudWatchClient{backend: b})
+}
+
+// Calls returns how many times the named API operation has been invoked.
+func (b *Backend) Calls(op string) int {
+	b.Lock()
+	defer b.Unlock()
+	return b.calls[op]
+}
+
+// Widgets returns the metric widget definitions rendered so far.
+func (b *Backend) Widgets() []string {
+	b.Lock()
+	defer b.Unlock()
+	return append([]string(nil), b.widgets...)
+}
+
+func (b *Backend) record(op string) {
+	b.calls[op]++
+}
+
+func invalidParameter(format string, args ...interface{}) error {
+	return awserr.New(ecs.ErrCodeInvalidParameterException, fmt.Sprintf(format, args...), nil)
+}
+
+// arnMatches reports whether ref names the resource with the given ARN, either
+// by the full ARN or by its final path component.
+func arnMatches(arn *string, ref string) bool {
+	a := aws.StringValue(arn)
+	if a == ref {
+		return true
+	}
+	toks := strings.Split(a, "/")
+	return len(toks) > 1 && toks[len(toks)-1] == ref
+}
+
+// checkContext fails the way the SDK does when a request's context is done.
+func checkContext(ctx aws.Context) error {
+	if err := ctx.Err(); err != nil {
+		return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+	return nil
+}
+
+// inCluster reports whether a resource ARN of the form
+// arn:...:kind/cluster/id belongs to the given cluster.
+func inCluster(arn *string, cluster *string) bool {
+	toks := strings.Split(aws.StringValue(arn), "/")
+	ref := strings.Split(clusterRef(cluster), "/")
+	return len(toks) == 3 && toks[1] == ref[len(ref)-1]
+}
+
+func clusterRef(cluster *string) string {
+	if cluster == nil {
+		return "default"
+	}
+	return *cluster
+}
+
+// page slices n items according to a NextToken/MaxResults pair, returning the
+// bounds and the token for the following page.
+func (b *Backend) page(n int, token *string, maxResults *int64, def int) (int, int, *string, error) {
+	start := 0
+	if token != nil {
+		var err error
+		if start, err = strconv.Atoi(*token); err != nil || start < 0 || start > n {
+			return 0, 0, nil, invalidParameter("invalid NextToken: %s", *token)
+		}
+	}
+	size := def
+	if maxResults != nil {
+		size = int(*maxResults)
+	}
+	if b.PageSize > 0 && b.PageSize < size {
+		size = b.PageSize
+	}
+	end := start + size
+	if end >= n {
+		return start, n, nil, nil
+	}
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
@@ -0,0 +1,168 @@
+package ecsfake
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// The fake simulates rollouts for services changed through UpdateService.
+// Each time such a service is described, its primary deployment starts one
+// more task and older deployments stop one, until it reaches a steady state.
+
+// copyService snapshots a service so callers never share state with later
+// simulation steps.
+func copyService(svc *ecs.Service) *ecs.Service {
+	res := &ecs.Service{}
+	awsutil.Copy(res, svc)
+	return res
+}
+
+func (b *Backend) findService(cluster, ref *string) *ecs.Service {
+	for _, svc := range b.fixture.Services {
+		if arnMatches(svc.ClusterArn, clusterRef(cluster)) &&
+			(arnMatches(svc.ServiceArn, *ref) || aws.StringValue(svc.ServiceName) == *ref) {
+			return svc
+		}
+	}
+	return nil
+}
+
+func (b *Backend) newDeployment(svc *ecs.Service) {
+	now := b.Now()
+	for _, d := range svc.Deployments {
+		if aws.StringValue(d.Status) == "PRIMARY" {
+			d.Status = aws.String("ACTIVE")
+			d.UpdatedAt = aws.Time(now)
+		}
+	}
+	b.deploymentSeq++
+	state := ecs.DeploymentRolloutStateInProgress
+	d := &ecs.Deployment{
+		Id:                 aws.String(fmt.Sprintf("ecs-svc/%019d", b.deploymentSeq)),
+		Status:             aws.String("PRIMARY"),
+		TaskDefinition:     svc.TaskDefinition,
+		DesiredCount:       svc.DesiredCount,
+		RunningCount:       aws.Int64(0),
+		PendingCount:       aws.Int64(0),
+		FailedTasks:        aws.Int64(0),
+		RolloutState:       aws.String(state),
+		RolloutStateReason: aws.String(fmt.Sprintf("ECS deployment ecs-svc/%019d in progress.", b.deploymentSeq)),
+		CreatedAt:          aws.Time(now),
+		UpdatedAt:          aws.Time(now),
+	}
+	svc.Deployments = append([]*ecs.Deployment{d}, svc.Deployments...)
+	b.addEvent(svc, fmt.Sprintf("(service %s) has started a deployment: %s.", aws.StringValue(svc.ServiceName),
+		aws.StringValue(d.Id)))
+}
+
+func (b *Backend) addEvent(svc *ecs.Service, msg string) {
+	b.eventSeq++
+	svc.Events = append([]*ecs.ServiceEvent{{
+		Id:        aws.String(fmt.Sprintf("fake-%d", b.eventSeq)),
+		CreatedAt: aws.Time(b.Now()),
+		Message:   aws.String(msg),
+	}}, svc.Events...)
+}
+
+// advance moves a rolling service one step closer to its steady state.
+func (b *Backend) advance(svc *ecs.Service) {
+	if !b.rolling[aws.StringValue(svc.ServiceArn)] || len(svc.Deployments) == 0 {
+		return
+	}
+	now := b.Now()
+	primary := svc.Deployments[0]
+	desired := aws.Int64Value(svc.DesiredCount)
+	primary.DesiredCount = aws.Int64(desired)
+	primary.UpdatedAt = aws.Time(now)
+	if b.FailDeployments && aws.StringValue(primary.RolloutState) == ecs.DeploymentRolloutStateInProgress {
+		primary.FailedTasks = aws.Int64(aws.Int64Value(primary.FailedTasks) + 1)
+		primary.RolloutState = aws.String(ecs.DeploymentRolloutStateFailed)
+		primary.RolloutStateReason = aws.String("ECS deployment circuit breaker: tasks failed to start.")
+		b.addEvent(svc, fmt.Sprintf("(service %s) (deployment %s) deployment failed: tasks failed to start.",
+			aws.StringValue(svc.ServiceName), aws.StringValue(primary.Id)))
+		delete(b.rolling, aws.StringValue(svc.ServiceArn))
+		return
+	}
+
+	running := aws.Int64Value(primary.RunningCount)
+	switch {
+	case running < desired:
+		running++
+	case running > desired:
+		running = desired
+	}
+	primary.RunningCount = aws.Int64(running)
+
+	var remaining []*ecs.Deployment
+	total := running
+	for _, d := range svc.Deployments[1:] {
+		if n := aws.Int64Value(d.RunningCount) - 1; n > 0 {
+			d.RunningCount = aws.Int64(n)
+			d.UpdatedAt = aws.Time(now)
+			remaining = append(remaining, d)
+			total += n
+		}
+	}
+	svc.Deployments = append([]*ecs.Deployment{primary}, remaining...)
+	svc.RunningCount = aws.Int64(total)
+	svc.PendingCount = aws.Int64(0)
+
+	if running == desired && len(remaining) == 0 {
+		primary.RolloutState = aws.String(ecs.DeploymentRolloutStateCompleted)
+		primary.RolloutStateReason = aws.String(fmt.Sprintf("ECS deployment %s completed.",
+			aws.StringValue(primary.Id)))
+		b.addEvent(svc, fmt.Sprintf("(service %s) (deployment %s) deployment completed.",
+			aws.StringValue(svc.ServiceName), aws.StringValue(primary.Id)))
+		b.addEvent(svc, fmt.Sprintf("(service %s) has reached a steady state.", aws.StringValue(svc.ServiceName)))
+		delete(b.rolling, aws.StringValue(svc.ServiceArn))
+	}
+}
+
+func (c *ECSClient) UpdateService(input *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error) {
+	b := c.backend
+	b.Lock()
+	defer b.Unlock()
+	b.record("UpdateService")
+
+	svc := b.findService(input.Cluster, input.Service)
+	if svc == nil {
+		return nil, awserr.New(ecs.ErrCodeServiceNotFoundException, "Service not found.", nil)
+	}
+	if input.DesiredCount != nil {
+		if *input.DesiredCount < 0 {
+			return nil, invalidParameter("desired count must be non-negative")
+		}
+		svc.DesiredCount = input.DesiredCount
+	}
+	newDeployment := aws.BoolValue(input.ForceNewDeployment)
+	if input.TaskDefinition != nil {
+		td := b.findTaskDefinition(*input.TaskDefinition)
+		if td == nil {
+			return nil, invalidParameter("task definition not found: %s", *input.TaskDefinition)
+		}
+		if aws.StringValue(td.TaskDefinitionArn) != aws.StringValue(svc.TaskDefinition) {
+			svc.TaskDefinition = td.TaskDefinitionArn
+			newDeployment = true
+		}
+	}
+	if newDeployment || len(svc.Deployments) == 0 {
+		b.newDeployment(svc)
+	}
+	b.rolling[aws.StringValue(svc.ServiceArn)] = true
+	return &ecs.UpdateServiceOutput{
+		Service: copyService(svc),
+	}, nil
+}
+
+func (c *ECSClient) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput,
+	opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	return c.UpdateService(input)
+}
@@ -0,0 +1,68 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+type ServiceEvent struct {
+	ID        string
+	CreatedAt time.Time
+	Message   string
+}
+
+// describeService fetches a single service by name or ARN.
+func (e *ECS) describeService(ctx context.Context, name string) (*ecs.Service, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+		Services: []*string{aws.String(name)},
+		Cluster:  aws.String(e.cluster()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Services) == 0 {
+		var reasons []string
+		for _, f := range resp.Failures {
+			reasons = append(reasons, aws.StringValue(f.Reason))
+		}
+		return nil, fmt.Errorf("service not found: %s (%s)", name, strings.Join(reasons, ", "))
+	}
+	return resp.Services[0], nil
+}
+
+// ServiceEvents returns the service's events created after since, oldest
+// first. ECS only retains the most recent 100 events for a service.
+func (e *ECS) ServiceEvents(service string, since time.Time) ([]ServiceEvent, error) {
+	return e.ServiceEventsWithContext(context.Background(), service, since)
+}
+
+func (e *ECS) ServiceEventsWithContext(ctx context.Context, service string, since time.Time) ([]ServiceEvent, error) {
+	svc, err := e.describeService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	var res []ServiceEvent
+	for _, ev := range svc.Events {
+		createdAt := aws.TimeValue(ev.CreatedAt).Local()
+		if !createdAt.After(since) {
+			continue
+		}
+		res = append(res, ServiceEvent{
+			ID:        aws.StringValue(ev.Id),
+			CreatedAt: createdAt,
+			Message:   aws.StringValue(ev.Message),
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
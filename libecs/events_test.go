@@ -0,0 +1,44 @@
+package libecs_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestServiceEvents(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		service string
+		since   time.Time
+		want    []string
+	}{
+		{"web", time.Time{}, []string{"e1", "e2", "e3"}},
+		{"web", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), []string{"e2", "e3"}},
+		// since itself is excluded.
+		{"web", time.Date(2026, 10, 3, 9, 0, 30, 0, time.UTC), []string{"e3"}},
+		{"web", time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC), nil},
+		{"worker", time.Time{}, []string{"e4"}},
+	}
+	for _, c := range cases {
+		t.Run(c.service+" since "+c.since.Format(time.RFC3339), func(t *testing.T) {
+			events, err := e.ServiceEvents(c.service, c.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ev := range events {
+				got = append(got, ev.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("events = %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if _, err := e.ServiceEvents("missing", time.Time{}); err == nil {
+		t.Errorf("ServiceEvents of a missing service succeeded")
+	}
+}
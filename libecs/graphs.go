@@ -0,0 +1,327 @@
+package libecs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// maxGraphSize is the largest width or height GetMetricWidgetImage accepts.
+const maxGraphSize = 2000
+
+// maxGraphPoints caps how many datapoints automatic period selection puts on
+// a single line.
+const maxGraphPoints = 1440
+
+// graphPeriods are the periods automatic selection picks from, shortest
+// first.
+var graphPeriods = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// TimeRange is the window a graph covers, either the last Duration up to
+// now or the absolute range from Start to End.
+type TimeRange struct {
+	Duration time.Duration
+	Start    time.Time
+	End      time.Time
+}
+
+// Last is the window of the given length ending now.
+func Last(d time.Duration) TimeRange {
+	return TimeRange{Duration: d}
+}
+
+// Between is the absolute window from start to end.
+func Between(start, end time.Time) TimeRange {
+	return TimeRange{Start: start, End: end}
+}
+
+// bounds resolves the range against the current time.
+func (r TimeRange) bounds(now time.Time) (time.Time, time.Time) {
+	if r.Duration > 0 {
+		return now.Add(-r.Duration), now
+	}
+	return r.Start, r.End
+}
+
+func (r TimeRange) validate() error {
+	if r.Duration > 0 {
+		return nil
+	}
+	if r.Start.IsZero() || r.End.IsZero() {
+		return fmt.Errorf("time range needs a duration or both a start and an end")
+	}
+	if !r.End.After(r.Start) {
+		return fmt.Errorf("time range ends before it starts")
+	}
+	return nil
+}
+
+// parseDuration extends time.ParseDuration with whole days (7d) and weeks
+// (2w).
+func parseDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates, which are taken to
+// be midnight UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
+
+// ParseTimeRange reads a relative window such as 1h, 6h or 7d, or an
+// absolute one written as start/end, with each end an RFC 3339 timestamp or
+// a date, e.g. 2024-05-01/2024-05-03.
+func ParseTimeRange(s string) (TimeRange, error) {
+	var r TimeRange
+	if idx := strings.Index(s, "/"); idx >= 0 {
+		start, err := parseTime(s[:idx])
+		if err != nil {
+			return TimeRange{}, err
+		}
+		end, err := parseTime(s[idx+1:])
+		if err != nil {
+			return TimeRange{}, err
+		}
+		r = Between(start, end)
+	} else {
+		d, err := parseDuration(s)
+		if err != nil {
+			return TimeRange{}, err
+		}
+		if d <= 0 {
+			return TimeRange{}, fmt.Errorf("time range must be positive: %s", s)
+		}
+		r = Last(d)
+	}
+	if err := r.validate(); err != nil {
+		return TimeRange{}, err
+	}
+	return r, nil
+}
+
+// GraphOptions controls the window and size of a graph. The zero value is
+// the last 24 hours at CloudWatch's default size.
+type GraphOptions struct {
+	Range TimeRange
+	// Period is the length of each datapoint. Zero picks one from the range,
+	// see autoPeriod.
+	Period time.Duration
+	// Width and Height are in pixels. Zero means the renderer's default.
+	Width  int
+	Height int
+	// Renderer draws the graph. Empty means RendererCloudWatch.
+	Renderer GraphRenderer
+}
+
+func (o GraphOptions) timeRange() TimeRange {
+	if o.Range == (TimeRange{}) {
+		return Last(24 * time.Hour)
+	}
+	return o.Range
+}
+
+func (o GraphOptions) validate() error {
+	if err := o.timeRange().validate(); err != nil {
+		return err
+	}
+	if o.Period < 0 || o.Period%time.Minute != 0 {
+		return fmt.Errorf("period must be a whole number of minutes: %s", o.Period)
+	}
+	if o.Width < 0 || o.Width > maxGraphSize || o.Height < 0 || o.Height > maxGraphSize {
+		return fmt.Errorf("graph size must be at most %dx%d", maxGraphSize, maxGraphSize)
+	}
+	switch o.Renderer {
+	case "", RendererCloudWatch, RendererPNG, RendererSVG:
+	default:
+		return fmt.Errorf("unknown graph renderer: %s", o.Renderer)
+	}
+	return nil
+}
+
+// autoPeriod picks the shortest period that keeps the graph under
+// maxGraphPoints per line and that CloudWatch still has data for, given
+// that it keeps minute data for 15 days and five minute data for 63.
+func autoPeriod(start, end, now time.Time) time.Duration {
+	span := end.Sub(start)
+	age := now.Sub(start)
+	for _, p := range graphPeriods {
+		switch {
+		case age > 63*24*time.Hour && p < time.Hour:
+			continue
+		case age > 15*24*time.Hour && p < 5*time.Minute:
+			continue
+		case span/p > maxGraphPoints:
+			continue
+		}
+		return p
+	}
+	return graphPeriods[len(graphPeriods)-1]
+}
+
+type graphAxis struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+type graphYAxis struct {
+	Left graphAxis `json:"left"`
+}
+
+// metricWidget is the GetMetricWidgetImage request document. Each metric is
+// namespace, name, dimension name/value pairs and then a rendering options
+// object.
+type metricWidget struct {
+	Title   string          `json:"title,omitempty"`
+	View    string          `json:"view"`
+	Start   string          `json:"start"`
+	End     string          `json:"end"`
+	Period  int64           `json:"period"`
+	Width   int             `json:"width,omitempty"`
+	Height  int             `json:"height,omitempty"`
+	YAxis   *graphYAxis     `json:"yAxis,omitempty"`
+	Metrics [][]interface{} `json:"metrics"`
+
+	Annotations *widgetAnnotations `json:"annotations,omitempty"`
+}
+
+type widgetAnnotations struct {
+	Horizontal []widgetAnnotation         `json:"horizontal,omitempty"`
+	Vertical   []widgetVerticalAnnotation `json:"vertical,omitempty"`
+}
+
+type widgetAnnotation struct {
+	Value float64 `json:"value"`
+	Label string  `json:"label,omitempty"`
+	Color string  `json:"color,omitempty"`
+}
+
+type widgetVerticalAnnotation struct {
+	Value time.Time `json:"value"`
+	Label string    `json:"label,omitempty"`
+	Color string    `json:"color,omitempty"`
+}
+
+type widgetMetricOptions struct {
+	ID    string `json:"id"`
+	Stat  string `json:"stat"`
+	Label string `json:"label,omitempty"`
+}
+
+// window resolves the options into the start, end and period to query.
+func (o GraphOptions) window(now time.Time) (time.Time, time.Time, time.Duration, error) {
+	if err := o.validate(); err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	start, end := o.timeRange().bounds(now)
+	period := o.Period
+	if period == 0 {
+		period = autoPeriod(start, end, now)
+	}
+	return start, end, period, nil
+}
+
+// newMetricWidget fills in the window, period and size common to all graphs.
+func newMetricWidget(title string, opts GraphOptions, now time.Time) (metricWidget, error) {
+	start, end, period, err := opts.window(now)
+	if err != nil {
+		return metricWidget{}, err
+	}
+	return metricWidget{
+		Title:  title,
+		View:   "timeSeries",
+		Start:  start.UTC().Format(time.RFC3339),
+		End:    end.UTC().Format(time.RFC3339),
+		Period: int64(period / time.Second),
+		Width:  opts.Width,
+		Height: opts.Height,
+	}, nil
+}
+
+func (e *ECS) renderMetricWidget(ctx context.Context, widget metricWidget) (io.Reader, error) {
+	doc, err := json.Marshal(widget)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	res, err := e.cloudwatch.GetMetricWidgetImageWithContext(ctx, &cloudwatch.GetMetricWidgetImageInput{
+		MetricWidget: aws.String(string(doc)),
+		OutputFormat: aws.String("png"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(res.MetricWidgetImage), nil
+}
+
+// ServiceGraphSpec is the spec GetServiceGraph draws: the average, maximum
+// and minimum of one of the service's AWS/ECS utilization metrics, such as
+// CPUUtilization or MemoryUtilization.
+func ServiceGraphSpec(svcname, metric string) GraphSpec {
+	zero, hundred := 0.0, 100.0
+	return GraphSpec{
+		Title: fmt.Sprintf("%s %s", svcname, metric),
+		Metrics: []GraphMetric{{
+			Namespace: "AWS/ECS",
+			Name:      metric,
+			Stats:     []string{"Average", "Maximum", "Minimum"},
+			Services:  []string{svcname},
+		}},
+		YMin: &zero,
+		YMax: &hundred,
+	}
+}
+
+// GetServiceGraph renders a graph of ServiceGraphSpec, marked with the
+// service's deployments in the window.
+func (e *ECS) GetServiceGraph(svcname, metric string, opts GraphOptions) (io.Reader, error) {
+	return e.GetServiceGraphWithContext(context.Background(), svcname, metric, opts)
+}
+
+func (e *ECS) GetServiceGraphWithContext(ctx context.Context, svcname, metric string,
+	opts GraphOptions) (io.Reader, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	spec := ServiceGraphSpec(svcname, metric)
+	markers, err := e.ServiceDeploymentMarkersWithContext(ctx, svcname, opts.Range)
+	if err != nil {
+		return nil, err
+	}
+	spec.Markers = markers
+	return e.GetGraphWithContext(ctx, spec, opts)
+}
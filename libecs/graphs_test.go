@@ -0,0 +1,178 @@
+package libecs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	day := 24 * time.Hour
+	cases := []struct {
+		in      string
+		want    TimeRange
+		wantErr bool
+	}{
+		{in: "6h", want: Last(6 * time.Hour)},
+		{in: "90m", want: Last(90 * time.Minute)},
+		{in: "7d", want: Last(7 * day)},
+		{in: "2w", want: Last(14 * day)},
+		{in: "2024-05-01/2024-05-03", want: Between(
+			time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC))},
+		{in: "2024-05-01T12:00:00Z/2024-05-01T18:30:00Z", want: Between(
+			time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC))},
+		{in: "", wantErr: true},
+		{in: "0h", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "xd", wantErr: true},
+		{in: "soon", wantErr: true},
+		{in: "2024-05-03/2024-05-01", wantErr: true},
+		{in: "2024-05-01/2024-05-01", wantErr: true},
+		{in: "2024-05-01/tomorrow", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, err := ParseTimeRange(c.in)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("ParseTimeRange(%q) = %+v, want an error", c.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeRange(%q): %s", c.in, err)
+			}
+			if !got.Start.Equal(c.want.Start) || !got.End.Equal(c.want.End) || got.Duration != c.want.Duration {
+				t.Errorf("ParseTimeRange(%q) = %+v, want %+v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestAutoPeriod(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       time.Duration
+	}{
+		{"last hour", now.Add(-time.Hour), now, time.Minute},
+		{"last day", now.Add(-day), now, time.Minute},
+		{"last two days", now.Add(-2 * day), now, 5 * time.Minute},
+		{"last week", now.Add(-7 * day), now, 15 * time.Minute},
+		{"last 30 days", now.Add(-30 * day), now, time.Hour},
+		{"last 300 days", now.Add(-300 * day), now, 6 * time.Hour},
+		{"last five years", now.Add(-5 * 365 * day), now, 24 * time.Hour},
+		// Minute data is gone after 15 days and five minute data after 63,
+		// however short the window.
+		{"hour 20 days ago", now.Add(-20 * day), now.Add(-20*day + time.Hour), 5 * time.Minute},
+		{"hour 90 days ago", now.Add(-90 * day), now.Add(-90*day + time.Hour), time.Hour},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := autoPeriod(c.start, c.end, now); got != c.want {
+				t.Errorf("autoPeriod() = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestDeploymentMarkers(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, 5, 1, hour, min, 0, 0, time.UTC)
+	}
+	deployment := func(id, taskDef, state string, created, updated time.Time) *ecs.Deployment {
+		return &ecs.Deployment{
+			Id:             aws.String(id),
+			TaskDefinition: aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/" + taskDef),
+			RolloutState:   aws.String(state),
+			CreatedAt:      aws.Time(created),
+			UpdatedAt:      aws.Time(updated),
+		}
+	}
+	event := func(when time.Time, msg string) *ecs.ServiceEvent {
+		return &ecs.ServiceEvent{CreatedAt: aws.Time(when), Message: aws.String(msg)}
+	}
+	type marker struct {
+		time  time.Time
+		label string
+	}
+	cases := []struct {
+		name        string
+		deployments []*ecs.Deployment
+		events      []*ecs.ServiceEvent
+		want        []marker
+	}{
+		{
+			name: "from deployments",
+			deployments: []*ecs.Deployment{
+				deployment("ecs-svc/2", "web:13", "IN_PROGRESS", at(12, 0), at(12, 5)),
+				deployment("ecs-svc/1", "web:12", "COMPLETED", at(9, 0), at(9, 10)),
+			},
+			want: []marker{
+				{at(9, 0), "web:12 started"},
+				{at(9, 10), "web:12 completed"},
+				{at(12, 0), "web:13 started"},
+			},
+		},
+		{
+			// UpdatedAt moves whenever a deployment scales, so the events'
+			// times win.
+			name: "events take precedence",
+			deployments: []*ecs.Deployment{
+				deployment("ecs-svc/2", "web:13", "FAILED", at(12, 0), at(14, 0)),
+				deployment("ecs-svc/1", "web:12", "COMPLETED", at(9, 0), at(11, 0)),
+			},
+			events: []*ecs.ServiceEvent{
+				event(at(12, 20), "(service web) (deployment ecs-svc/2) deployment failed: tasks failed to start."),
+				event(at(11, 59), "(service web) has started a deployment: ecs-svc/2."),
+				event(at(9, 10), "(service web) (deployment ecs-svc/1) deployment completed."),
+				event(at(9, 1), "(service web) has reached a steady state."),
+			},
+			want: []marker{
+				{at(9, 0), "web:12 started"},
+				{at(9, 10), "web:12 completed"},
+				{at(11, 59), "web:13 started"},
+				{at(12, 20), "web:13 failed"},
+			},
+		},
+		{
+			// Deployments that have since been removed from the service are
+			// only known from their events.
+			name: "events only",
+			events: []*ecs.ServiceEvent{
+				event(at(10, 5), "(service web) (deployment ecs-svc/0) deployment completed."),
+				event(at(10, 0), "(service web) has started a deployment: ecs-svc/0"),
+			},
+			want: []marker{
+				{at(10, 0), "deployment started"},
+				{at(10, 5), "deployment completed"},
+			},
+		},
+		{
+			name: "outside the window",
+			deployments: []*ecs.Deployment{
+				deployment("ecs-svc/1", "web:12", "COMPLETED", at(1, 0), at(23, 0)),
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := &ecs.Service{Deployments: c.deployments, Events: c.events}
+			var got []marker
+			for _, m := range deploymentMarkers(svc, at(6, 0), at(18, 0)) {
+				if m.Color == "" {
+					t.Errorf("marker %q has no color", m.Label)
+				}
+				got = append(got, marker{m.Time.UTC(), m.Label})
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("markers = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
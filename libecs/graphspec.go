@@ -0,0 +1,311 @@
+package libecs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+)
+
+// percentileStat matches CloudWatch percentile statistics such as p50, p99
+// and p99.9.
+var percentileStat = regexp.MustCompile(`^p(\d{1,2}(\.\d+)?|100)$`)
+
+func validateStat(stat string) error {
+	switch stat {
+	case "Average", "Sum", "Minimum", "Maximum", "SampleCount":
+		return nil
+	}
+	if percentileStat.MatchString(stat) {
+		return nil
+	}
+	return fmt.Errorf("unknown statistic: %s", stat)
+}
+
+// GraphMetric is one CloudWatch metric to plot. Each statistic gets its own
+// line. Setting Services or Clusters overlays one copy of the metric per
+// service or cluster, adding ServiceName and ClusterName dimensions; with
+// Services but no Clusters the ECS object's cluster is used.
+type GraphMetric struct {
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	// Stats defaults to Average.
+	Stats    []string `json:"stats,omitempty"`
+	Services []string `json:"services,omitempty"`
+	Clusters []string `json:"clusters,omitempty"`
+	// Label prefixes the legend of each line, in place of the service,
+	// cluster or metric name.
+	Label string `json:"label,omitempty"`
+}
+
+// GraphThreshold is a horizontal line across the graph.
+type GraphThreshold struct {
+	Value float64 `json:"value"`
+	Label string  `json:"label,omitempty"`
+	// Color is a hex color such as #d62728. Empty lets CloudWatch pick.
+	Color string `json:"color,omitempty"`
+}
+
+// GraphMarker is a vertical line at a moment on the graph, such as a
+// deployment starting.
+type GraphMarker struct {
+	Time  time.Time `json:"time"`
+	Label string    `json:"label,omitempty"`
+	Color string    `json:"color,omitempty"`
+}
+
+// GraphSpec describes a graph of any number of CloudWatch metrics.
+type GraphSpec struct {
+	Title      string           `json:"title,omitempty"`
+	Metrics    []GraphMetric    `json:"metrics"`
+	Thresholds []GraphThreshold `json:"thresholds,omitempty"`
+	Markers    []GraphMarker    `json:"markers,omitempty"`
+	// YMin and YMax fix the left axis. Nil fits it to the data.
+	YMin *float64 `json:"ymin,omitempty"`
+	YMax *float64 `json:"ymax,omitempty"`
+}
+
+func (s GraphSpec) validate() error {
+	if len(s.Metrics) == 0 {
+		return fmt.Errorf("graph has no metrics")
+	}
+	for _, m := range s.Metrics {
+		if m.Namespace == "" || m.Name == "" {
+			return fmt.Errorf("graph metric needs a namespace and a name")
+		}
+		for _, stat := range m.Stats {
+			if err := validateStat(stat); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// graphLine is a single plotted series after expanding services, clusters
+// and statistics.
+type graphLine struct {
+	namespace, name string
+	dims            map[string]string
+	stat, label     string
+}
+
+func (e *ECS) graphLines(m GraphMetric) []graphLine {
+	type target struct {
+		dims  map[string]string
+		label string
+	}
+	withDims := func(extra map[string]string) map[string]string {
+		dims := make(map[string]string, len(m.Dimensions)+len(extra))
+		for k, v := range m.Dimensions {
+			dims[k] = v
+		}
+		for k, v := range extra {
+			dims[k] = v
+		}
+		return dims
+	}
+
+	clusters := m.Clusters
+	if len(clusters) == 0 && len(m.Services) > 0 {
+		clusters = []string{e.cluster()}
+	}
+	var targets []target
+	switch {
+	case len(m.Services) > 0:
+		for _, cluster := range clusters {
+			for _, svc := range m.Services {
+				label := svc
+				if len(clusters) > 1 {
+					label = cluster + "/" + svc
+				}
+				targets = append(targets, target{
+					dims:  withDims(map[string]string{"ClusterName": cluster, "ServiceName": svc}),
+					label: label,
+				})
+			}
+		}
+	case len(clusters) > 0:
+		for _, cluster := range clusters {
+			targets = append(targets, target{
+				dims:  withDims(map[string]string{"ClusterName": cluster}),
+				label: cluster,
+			})
+		}
+	default:
+		targets = append(targets, target{dims: withDims(nil), label: m.Name})
+	}
+
+	stats := m.Stats
+	if len(stats) == 0 {
+		stats = []string{"Average"}
+	}
+	var res []graphLine
+	for _, t := range targets {
+		label := t.label
+		if m.Label != "" {
+			label = m.Label
+			if len(targets) > 1 {
+				label += " " + t.label
+			}
+		}
+		for _, stat := range stats {
+			res = append(res, graphLine{
+				namespace: m.Namespace,
+				name:      m.Name,
+				dims:      t.dims,
+				stat:      stat,
+				label:     label + " " + stat,
+			})
+		}
+	}
+	return res
+}
+
+// GetChart fetches the data for a graph for drawing with a local renderer,
+// with the time axis set to the requested window.
+func (e *ECS) GetChart(spec GraphSpec, opts GraphOptions) (Chart, error) {
+	return e.GetChartWithContext(context.Background(), spec, opts)
+}
+
+func (e *ECS) GetChartWithContext(ctx context.Context, spec GraphSpec, opts GraphOptions) (Chart, error) {
+	start, end, _, err := opts.window(time.Now())
+	if err != nil {
+		return Chart{}, err
+	}
+	series, err := e.GetMetricSeriesWithContext(ctx, spec, opts)
+	if err != nil {
+		return Chart{}, err
+	}
+	return Chart{
+		Title:      spec.Title,
+		Series:     series,
+		Thresholds: spec.Thresholds,
+		Markers:    spec.Markers,
+		YMin:       spec.YMin,
+		YMax:       spec.YMax,
+		Start:      start,
+		End:        end,
+		Width:      opts.Width,
+		Height:     opts.Height,
+	}, nil
+}
+
+// GetGraph renders a graph of the metrics in the spec. CloudWatch draws it
+// as a PNG unless opts selects a local renderer.
+func (e *ECS) GetGraph(spec GraphSpec, opts GraphOptions) (io.Reader, error) {
+	return e.GetGraphWithContext(context.Background(), spec, opts)
+}
+
+func (e *ECS) GetGraphWithContext(ctx context.Context, spec GraphSpec, opts GraphOptions) (io.Reader, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Renderer != "" && opts.Renderer != RendererCloudWatch {
+		chart, err := e.GetChartWithContext(ctx, spec, opts)
+		if err != nil {
+			return nil, err
+		}
+		return renderChart(opts.Renderer, chart)
+	}
+	widget, err := newMetricWidget(spec.Title, opts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if spec.YMin != nil || spec.YMax != nil {
+		widget.YAxis = &graphYAxis{Left: graphAxis{Min: spec.YMin, Max: spec.YMax}}
+	}
+	for _, m := range spec.Metrics {
+		for _, line := range e.graphLines(m) {
+			row := []interface{}{line.namespace, line.name}
+			for _, name := range sortedKeys(line.dims) {
+				row = append(row, name, line.dims[name])
+			}
+			row = append(row, widgetMetricOptions{
+				ID:    fmt.Sprintf("m%d", len(widget.Metrics)+1),
+				Stat:  line.stat,
+				Label: line.label,
+			})
+			widget.Metrics = append(widget.Metrics, row)
+		}
+	}
+	for _, t := range spec.Thresholds {
+		if widget.Annotations == nil {
+			widget.Annotations = &widgetAnnotations{}
+		}
+		widget.Annotations.Horizontal = append(widget.Annotations.Horizontal, widgetAnnotation{
+			Value: t.Value,
+			Label: t.Label,
+			Color: t.Color,
+		})
+	}
+	for _, m := range spec.Markers {
+		if widget.Annotations == nil {
+			widget.Annotations = &widgetAnnotations{}
+		}
+		widget.Annotations.Vertical = append(widget.Annotations.Vertical, widgetVerticalAnnotation{
+			Value: m.Time.UTC(),
+			Label: m.Label,
+			Color: m.Color,
+		})
+	}
+	return e.renderMetricWidget(ctx, widget)
+}
+
+// graphSpecFile is the on-disk form of a spec, which can also carry the
+// graph options.
+type graphSpecFile struct {
+	GraphSpec
+	Range  string `json:"range,omitempty"`
+	Period string `json:"period,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// ParseGraphSpec reads a JSON graph spec. Besides the GraphSpec fields it may
+// set range (as in ParseTimeRange), period (e.g. 5m), width and height.
+func ParseGraphSpec(r io.Reader) (GraphSpec, GraphOptions, error) {
+	var file graphSpecFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return GraphSpec{}, GraphOptions{}, fmt.Errorf("invalid graph spec: %s", err)
+	}
+	opts := GraphOptions{
+		Width:  file.Width,
+		Height: file.Height,
+	}
+	var err error
+	if file.Range != "" {
+		if opts.Range, err = ParseTimeRange(file.Range); err != nil {
+			return GraphSpec{}, GraphOptions{}, err
+		}
+	}
+	if file.Period != "" {
+		if opts.Period, err = time.ParseDuration(file.Period); err != nil {
+			return GraphSpec{}, GraphOptions{}, err
+		}
+	}
+	if err := file.GraphSpec.validate(); err != nil {
+		return GraphSpec{}, GraphOptions{}, err
+	}
+	return file.GraphSpec, opts, nil
+}
+
+// LoadGraphSpec reads a graph spec file, see ParseGraphSpec.
+func LoadGraphSpec(path string) (GraphSpec, GraphOptions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return GraphSpec{}, GraphOptions{}, err
+	}
+	defer f.Close()
+	spec, opts, err := ParseGraphSpec(f)
+	if err != nil {
+		return GraphSpec{}, GraphOptions{}, fmt.Errorf("%s: %s", path, err)
+	}
+	return spec, opts, nil
+}
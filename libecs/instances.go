@@ -0,0 +1,118 @@
+package libecs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+type Instance struct {
+	Arn              string
+	EC2InstanceID    string
+	InstanceType     string
+	AvailabilityZone string
+	AgentVersion     string
+	AgentConnected   bool
+	// Status is ACTIVE, DRAINING, or one of the registration states.
+	Status string
+	// CPU is in CPU units (1024 per vCPU) and memory in MiB, as registered
+	// with ECS and as left over after task reservations.
+	RegisteredCPU    int
+	RemainingCPU     int
+	RegisteredMemory int
+	RemainingMemory  int
+	RunningTasks     int
+	PendingTasks     int
+}
+
+// ReservedCPUPercent is the share of the instance's CPU reserved by tasks.
+func (i Instance) ReservedCPUPercent() float64 {
+	return reservedPercent(i.RegisteredCPU, i.RemainingCPU)
+}
+
+// ReservedMemoryPercent is the share of the instance's memory reserved by
+// tasks.
+func (i Instance) ReservedMemoryPercent() float64 {
+	return reservedPercent(i.RegisteredMemory, i.RemainingMemory)
+}
+
+func reservedPercent(registered, remaining int) float64 {
+	if registered == 0 {
+		return 0
+	}
+	return float64(registered-remaining) / float64(registered) * 100
+}
+
+func resourceValue(resources []*ecs.Resource, name string) int {
+	for _, r := range resources {
+		if aws.StringValue(r.Name) == name {
+			return int(aws.Int64Value(r.IntegerValue))
+		}
+	}
+	return 0
+}
+
+func attributeValue(attrs []*ecs.Attribute, name string) string {
+	for _, a := range attrs {
+		if aws.StringValue(a.Name) == name {
+			return aws.StringValue(a.Value)
+		}
+	}
+	return ""
+}
+
+func newInstance(ci *ecs.ContainerInstance) Instance {
+	inst := Instance{
+		Arn:              aws.StringValue(ci.ContainerInstanceArn),
+		EC2InstanceID:    aws.StringValue(ci.Ec2InstanceId),
+		InstanceType:     attributeValue(ci.Attributes, "ecs.instance-type"),
+		AvailabilityZone: attributeValue(ci.Attributes, "ecs.availability-zone"),
+		AgentConnected:   aws.BoolValue(ci.AgentConnected),
+		Status:           aws.StringValue(ci.Status),
+		RegisteredCPU:    resourceValue(ci.RegisteredResources, "CPU"),
+		RemainingCPU:     resourceValue(ci.RemainingResources, "CPU"),
+		RegisteredMemory: resourceValue(ci.RegisteredResources, "MEMORY"),
+		RemainingMemory:  resourceValue(ci.RemainingResources, "MEMORY"),
+		RunningTasks:     int(aws.Int64Value(ci.RunningTasksCount)),
+		PendingTasks:     int(aws.Int64Value(ci.PendingTasksCount)),
+	}
+	if ci.VersionInfo != nil {
+		inst.AgentVersion = aws.StringValue(ci.VersionInfo.AgentVersion)
+	}
+	return inst
+}
+
+// ListContainerInstances returns every container instance registered to the
+// cluster, including draining ones.
+func (e *ECS) ListContainerInstances() ([]Instance, error) {
+	return e.ListContainerInstancesWithContext(context.Background())
+}
+
+func (e *ECS) ListContainerInstancesWithContext(ctx context.Context) ([]Instance, error) {
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	var arns []string
+	err := e.ecs.ListContainerInstancesPagesWithContext(cctx, &ecs.ListContainerInstancesInput{
+		Cluster:    aws.String(e.cluster()),
+		MaxResults: aws.Int64(100),
+	}, func(page *ecs.ListContainerInstancesOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(page.ContainerInstanceArns)...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	described, err := e.describeContainerInstances(ctx, arns)
+	if err != nil {
+		return nil, err
+	}
+	var res []Instance
+	for _, arn := range arns {
+		if ci, ok := described[arn]; ok {
+			res = append(res, newInstance(ci))
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package libecs_test
+
+import (
+	"testing"
+
+	"github.com/mmaxim/ecstools/libecs"
+	"github.com/mmaxim/ecstools/libecs/ecsfake"
+)
+
+func TestListContainerInstances(t *testing.T) {
+	b := ecsfake.New(loadFixture(t))
+	b.PageSize = 1
+	e := b.NewECS(libecs.ECSConfig{Cluster: "prod", Region: fixtureRegion})
+	instances, err := e.ListContainerInstances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("got %d instances, want 2", len(instances))
+	}
+	inst := instances[0]
+	if inst.EC2InstanceID != "i-0123456789abcdef0" || inst.InstanceType != "m5.large" ||
+		inst.AvailabilityZone != "us-east-1a" || !inst.AgentConnected {
+		t.Errorf("instance = %+v", inst)
+	}
+	if got := inst.ReservedCPUPercent(); got != 25 {
+		t.Errorf("reserved CPU = %f%%, want 25%%", got)
+	}
+	if got := inst.ReservedMemoryPercent(); got != 20 {
+		t.Errorf("reserved memory = %f%%, want 20%%", got)
+	}
+
+	if got := (libecs.Instance{}).ReservedCPUPercent(); got != 0 {
+		t.Errorf("reserved CPU with nothing registered = %f%%, want 0", got)
+	}
+}
@@ -0,0 +1,208 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const (
+	// Maximum number of queries accepted by a single GetMetricData call.
+	metricDataBatchSize = 500
+	// Maximum number of container instances accepted by
+	// DescribeContainerInstances.
+	describeInstancesBatchSize = 100
+)
+
+// latestMetricWindow is how far back to look for the current value of a
+// metric. Each query is answered with the newest datapoint in the window.
+const latestMetricWindow = 2 * time.Minute
+
+func metricStatQuery(id, namespace, metric string, dims map[string]string, stat string,
+	period int64) *cloudwatch.MetricDataQuery {
+	var cwdims []*cloudwatch.Dimension
+	for _, name := range sortedKeys(dims) {
+		cwdims = append(cwdims, &cloudwatch.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(dims[name]),
+		})
+	}
+	return &cloudwatch.MetricDataQuery{
+		Id: aws.String(id),
+		MetricStat: &cloudwatch.MetricStat{
+			Metric: &cloudwatch.Metric{
+				Namespace:  aws.String(namespace),
+				MetricName: aws.String(metric),
+				Dimensions: cwdims,
+			},
+			Period: aws.Int64(period),
+			Stat:   aws.String(stat),
+		},
+		ReturnData: aws.Bool(true),
+	}
+}
+
+// latestMetricValues runs the queries through GetMetricData in batches and
+// returns the newest value for each, in query order. Queries with no data in
+// the window get 0.
+func (e *ECS) latestMetricValues(ctx context.Context, queries []*cloudwatch.MetricDataQuery) ([]float64, error) {
+	end := time.Now()
+	start := end.Add(-latestMetricWindow)
+	index := make(map[string]int, len(queries))
+	for i, q := range queries {
+		index[aws.StringValue(q.Id)] = i
+	}
+
+	res := make([]float64, len(queries))
+	found := make([]bool, len(queries))
+	numBatches := (len(queries) + metricDataBatchSize - 1) / metricDataBatchSize
+	err := forEach(numBatches, e.concurrency(), func(b int) error {
+		lim := (b + 1) * metricDataBatchSize
+		if lim > len(queries) {
+			lim = len(queries)
+		}
+		ctx, cancel := e.callContext(ctx)
+		defer cancel()
+		return e.cloudwatch.GetMetricDataPagesWithContext(ctx, &cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries[b*metricDataBatchSize : lim],
+			StartTime:         aws.Time(start),
+			EndTime:           aws.Time(end),
+			ScanBy:            aws.String(cloudwatch.ScanByTimestampDescending),
+		}, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, r := range page.MetricDataResults {
+				// Results are newest first, and a query's values can span
+				// pages, so only the first value seen for an ID counts.
+				i, ok := index[aws.StringValue(r.Id)]
+				if !ok || found[i] || len(r.Values) == 0 {
+					continue
+				}
+				res[i] = aws.Float64Value(r.Values[0])
+				found[i] = true
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// fillServiceMetrics fetches CPU and memory utilization for all of the given
+// services with as few GetMetricData calls as possible.
+func (e *ECS) fillServiceMetrics(ctx context.Context, svcs []*Service) error {
+	var queries []*cloudwatch.MetricDataQuery
+	for i, svc := range svcs {
+		dims := map[string]string{
+			"ClusterName": e.cluster(),
+			"ServiceName": svc.Name,
+		}
+		queries = append(queries,
+			metricStatQuery(fmt.Sprintf("cpu%d", i), "AWS/ECS", "CPUUtilization", dims, "Average", 60),
+			metricStatQuery(fmt.Sprintf("mem%d", i), "AWS/ECS", "MemoryUtilization", dims, "Average", 60))
+	}
+	values, err := e.latestMetricValues(ctx, queries)
+	if err != nil {
+		return err
+	}
+	for i, svc := range svcs {
+		svc.Metrics = ServiceMetrics{
+			CPU:    values[2*i],
+			Memory: values[2*i+1],
+		}
+	}
+	return nil
+}
+
+// describeContainerInstances describes the given container instances in
+// batches, keyed by container instance ARN.
+func (e *ECS) describeContainerInstances(ctx context.Context, arns []string) (map[string]*ecs.ContainerInstance, error) {
+	res := make(map[string]*ecs.ContainerInstance, len(arns))
+	for batchIndex := 0; batchIndex < len(arns); batchIndex += describeInstancesBatchSize {
+		lim := batchIndex + describeInstancesBatchSize
+		if lim >= len(arns) {
+			lim = len(arns)
+		}
+		cctx, cancel := e.callContext(ctx)
+		resp, err := e.ecs.DescribeContainerInstancesWithContext(cctx, &ecs.DescribeContainerInstancesInput{
+			Cluster:            aws.String(e.cluster()),
+			ContainerInstances: aws.StringSlice(arns[batchIndex:lim]),
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range resp.ContainerInstances {
+			res[aws.StringValue(inst.ContainerInstanceArn)] = inst
+		}
+	}
+	return res, nil
+}
+
+// fillInstanceMetrics resolves the EC2 instance behind each task's container
+// instance and attaches its CPU utilization. Each distinct instance is looked
+// up once, and all of them share batched API calls.
+func (e *ECS) fillInstanceMetrics(ctx context.Context, tasks []*Task) error {
+	var arns []string
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		if len(t.InstanceArn) > 0 && !seen[t.InstanceArn] {
+			seen[t.InstanceArn] = true
+			arns = append(arns, t.InstanceArn)
+		}
+	}
+	if len(arns) == 0 {
+		return nil
+	}
+
+	instances, err := e.describeContainerInstances(ctx, arns)
+	if err != nil {
+		return err
+	}
+	var queries []*cloudwatch.MetricDataQuery
+	var found, ids []string
+	for _, arn := range arns {
+		inst, ok := instances[arn]
+		if !ok {
+			// Stopped tasks can outlive their instance's registration
+			continue
+		}
+		id := aws.StringValue(inst.Ec2InstanceId)
+		queries = append(queries, metricStatQuery(fmt.Sprintf("cpu%d", len(ids)), "AWS/EC2",
+			"CPUUtilization", map[string]string{"InstanceId": id}, "Average", 60))
+		found = append(found, arn)
+		ids = append(ids, id)
+	}
+	values, err := e.latestMetricValues(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	byArn := make(map[string]InstanceMetrics, len(found))
+	for i, arn := range found {
+		byArn[arn] = InstanceMetrics{
+			CPU: values[i],
+			ID:  ids[i],
+		}
+	}
+	for _, t := range tasks {
+		if im, ok := byArn[t.InstanceArn]; ok {
+			t.InstanceMetrics = &im
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -6,7 +6,9 @@ import (
 	"io"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/reconquest/loreley"
 )
 
@@ -14,6 +16,9 @@ func getInstanceID(task Task) string {
 	if task.InstanceMetrics != nil {
 		return task.InstanceMetrics.ID
 	}
+	if task.LaunchType == ecs.LaunchTypeFargate {
+		return "fargate"
+	}
 	return "n/a"
 }
 
@@ -24,9 +29,346 @@ func getInstanceCPU(task Task) string {
 	return "n/a"
 }
 
+func getTaskMetric(task Task, value func(TaskMetrics) float64) string {
+	if task.TaskMetrics == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%f%%", value(*task.TaskMetrics))
+}
+
+// getTaskCPU is the task's own CPU usage, which is n/a unless task metrics
+// were fetched, see ECSConfig.TaskMetrics. It is kept apart from the host's
+// usage in getInstanceCPU so that neither is mistaken for the other.
+func getTaskCPU(task Task) string {
+	return getTaskMetric(task, TaskMetrics.CPUPercent)
+}
+
+func getTaskMemory(task Task) string {
+	return getTaskMetric(task, TaskMetrics.MemoryPercent)
+}
+
+func getLaunchType(task Task) string {
+	if task.CapacityProvider != "" {
+		return task.CapacityProvider
+	}
+	return orNA(task.LaunchType)
+}
+
+func getServiceLaunchType(s Service) string {
+	if len(s.CapacityProviders) > 0 {
+		return strings.Join(s.CapacityProviders, ",")
+	}
+	return orNA(s.LaunchType)
+}
+
+func basicHeader(header string) string {
+	return header
+}
+
+func colorHeader(header string) string {
+	return "<fg 13><bold>" + header + "<reset>"
+}
+
+// writeFormatted expands the loreley color tags in buffer and writes the
+// result to out.
+func writeFormatted(buffer *bytes.Buffer, out io.Writer) error {
+	loreley.DelimLeft = "<"
+	loreley.DelimRight = ">"
+	result, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("error formating output: %s", err.Error())
+	}
+
+	if _, err = out.Write([]byte(result)); err != nil {
+		return fmt.Errorf("error writing output: %s", err.Error())
+	}
+
+	return nil
+}
+
+func writeClusters(out io.Writer, clusters []Cluster, header func(string) string) error {
+	w := tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
+	fmt.Fprintf(w, "%s\n", header("Name\tStatus\tInstances\tServices\tRunning\tPending\tCapacity Providers"))
+	for _, c := range clusters {
+		providers := "n/a"
+		if len(c.CapacityProviders) > 0 {
+			providers = strings.Join(c.CapacityProviders, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n", c.Name, c.Status, c.RegisteredInstances,
+			c.ActiveServices, c.RunningTasks, c.PendingTasks, providers)
+	}
+	return w.Flush()
+}
+
+func getAgent(inst Instance) string {
+	if !inst.AgentConnected {
+		return inst.AgentVersion + " (disconnected)"
+	}
+	return inst.AgentVersion
+}
+
+func writeInstances(out io.Writer, instances []Instance, header func(string) string) error {
+	w := tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
+	fmt.Fprintf(w, "%s\n", header("Instance ID\tType\tAZ\tStatus\tAgent\tRunning\tPending\t"+
+		"CPU Free\tCPU Reserved%\tMemory Free\tMemory Reserved%"))
+	for _, inst := range instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d/%d\t%f\t%d/%d\t%f\n", inst.EC2InstanceID,
+			inst.InstanceType, inst.AvailabilityZone, inst.Status, getAgent(inst), inst.RunningTasks,
+			inst.PendingTasks, inst.RemainingCPU, inst.RegisteredCPU, inst.ReservedCPUPercent(),
+			inst.RemainingMemory, inst.RegisteredMemory, inst.ReservedMemoryPercent())
+	}
+	return w.Flush()
+}
+
+func colorDeployState(svc Service) string {
+	state := svc.DeployState()
+	switch {
+	case svc.DeploymentFailed():
+		return "<fg 9>" + state + "<reset>"
+	case svc.DeploymentInProgress():
+		return "<fg 11>" + state + "<reset>"
+	default:
+		return state
+	}
+}
+
+// writeDeployments lists every deployment of the services that are mid-rollout
+// or whose last rollout failed. It writes nothing if all services are steady.
+func writeDeployments(w io.Writer, services []Service, header func(string) string,
+	truncate func(string) string) {
+	var rolling []Service
+	for _, svc := range services {
+		if svc.DeploymentInProgress() || svc.DeploymentFailed() {
+			rolling = append(rolling, svc)
+		}
+	}
+	if len(rolling) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", header("Service\tDeployment\tTask\tDesired\tRunning\tPending\tFailed\t"+
+		"Created At\tUpdated At\tRollout"))
+	for _, svc := range rolling {
+		for _, d := range svc.Deployments {
+			rollout := d.RolloutState
+			if d.RolloutState == ecs.DeploymentRolloutStateFailed && d.RolloutStateReason != "" {
+				rollout += ": " + EscapeTags(d.RolloutStateReason)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n", svc.Name, d.Status,
+				truncate(d.TaskDefinition), d.DesiredCount, d.RunningCount, d.PendingCount, d.FailedTasks,
+				d.CreatedAt.Format("01-02-2006 15:04"), d.UpdatedAt.Format("01-02-2006 15:04"), rollout)
+		}
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// alarmsLookedUp reports whether the services' alarms were fetched, see
+// ECSConfig.Alarms. Service listings leave out the Alarms column otherwise.
+func alarmsLookedUp(services []Service) bool {
+	for _, s := range services {
+		if s.Alarms != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func colorAlarmState(svc Service) string {
+	state := svc.AlarmState()
+	if len(svc.FiringAlarms()) > 0 {
+		return "<fg 9>" + state + "<reset>"
+	}
+	return state
+}
+
+// writeFiringAlarms lists every alarm in the ALARM state across the services.
+// It writes nothing if none are firing.
+func writeFiringAlarms(w io.Writer, services []Service, header func(string) string) {
+	var rows []string
+	for _, svc := range services {
+		for _, a := range svc.FiringAlarms() {
+			rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%s\n", svc.Name, EscapeTags(a.Name),
+				formatTime(a.UpdatedAt), EscapeTags(a.Reason)))
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", header("Service\tAlarms Firing\tSince\tReason"))
+	for _, row := range rows {
+		fmt.Fprint(w, row)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "n/a"
+	}
+	return t.Format("01-02-2006 15:04:05")
+}
+
+// shortTaskID returns the task ID at the end of a task ARN. Unlike other ARNs
+// the second path component of a new style task ARN is the cluster name.
+func shortTaskID(arn string) string {
+	toks := strings.Split(arn, "/")
+	return toks[len(toks)-1]
+}
+
+func getExitCode(c Container) string {
+	if c.ExitCode == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%d", *c.ExitCode)
+}
+
+func getImageDigest(c Container) string {
+	// sha256: plus the first 12 hex digits, the same length docker shows
+	if len(c.ImageDigest) > 19 {
+		return c.ImageDigest[:19]
+	}
+	if c.ImageDigest == "" {
+		return "n/a"
+	}
+	return c.ImageDigest
+}
+
+func orNA(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return s
+}
+
+func writeTaskDetail(out io.Writer, task Task, header func(string) string, truncate func(string) string) error {
+	w := tabwriter.NewWriter(out, 0, 3, 2, ' ', tabwriter.FilterHTML)
+	fields := []struct {
+		name, value string
+	}{
+		{"Task", shortTaskID(task.Arn)},
+		{"Task Definition", truncate(task.TaskDefinition)},
+		{"Status", task.Status},
+		{"Desired", task.DesiredStatus},
+		{"Health", orNA(task.HealthStatus)},
+		{"Created At", formatTime(task.CreatedAt)},
+		{"Started At", formatTime(task.StartedAt)},
+		{"Stopped At", formatTime(task.StoppedAt)},
+		{"Stop Code", orNA(task.StopCode)},
+		{"Stopped Reason", EscapeTags(orNA(task.StoppedReason))},
+		{"Launch Type", orNA(task.LaunchType)},
+		{"Capacity Provider", orNA(task.CapacityProvider)},
+		{"Platform Version", orNA(task.PlatformVersion)},
+		{"Availability Zone", orNA(task.AvailabilityZone)},
+		{"Private IP", orNA(task.PrivateIP)},
+		{"Instance ID", getInstanceID(task)},
+		{"Instance CPU%", getInstanceCPU(task)},
+		{"Task CPU%", getTaskCPU(task)},
+		{"Task Memory%", getTaskMemory(task)},
+	}
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s\t%s\n", header(f.name+":"), f.value)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n")
+
+	w = tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
+	fmt.Fprintf(w, "%s\n", header("Container\tStatus\tHealth\tExit Code\tCPU\tMemory\tImage\tDigest\tReason"))
+	for _, c := range task.Containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d/%d\t%s\t%s\t%s\n", c.Name, c.Status, orNA(c.HealthStatus),
+			getExitCode(c), c.CPU, c.MemoryReservation, c.Memory, c.Image, getImageDigest(c),
+			EscapeTags(orNA(c.Reason)))
+	}
+	return w.Flush()
+}
+
+func getExitCodes(task Task) string {
+	var codes []string
+	for _, c := range task.Containers {
+		if c.ExitCode != nil {
+			codes = append(codes, fmt.Sprintf("%s=%d", c.Name, *c.ExitCode))
+		}
+	}
+	if len(codes) == 0 {
+		return "n/a"
+	}
+	return strings.Join(codes, ",")
+}
+
+// writeTasks lists tasks one per line. If any of them has stopped, columns
+// explaining why are added.
+func writeTasks(out io.Writer, tasks []Task, header func(string) string, truncate func(string) string) error {
+	stopped := false
+	for _, task := range tasks {
+		if !task.StoppedAt.IsZero() || task.StopCode != "" {
+			stopped = true
+			break
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
+	if stopped {
+		fmt.Fprintf(w, "%s\n", header("ID\tStatus\tDesired\tTask\tLaunch\tCreated At\tInstance ID\tInstance CPU%\t"+
+			"Task CPU%\tTask Memory%\t"+
+			"Stopped At\tStop Code\tExit Codes\tStopped Reason"))
+	} else {
+		fmt.Fprintf(w, "%s\n", header("ID\tStatus\tDesired\tTask\tLaunch\tCreated At\tInstance ID\tInstance CPU%\t"+
+			"Task CPU%\tTask Memory%"))
+	}
+	for _, task := range tasks {
+		ca := task.CreatedAt.Format("01-02-2006 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", shortTaskID(task.Arn), task.Status,
+			task.DesiredStatus, truncate(task.TaskDefinition), getLaunchType(task), ca, getInstanceID(task),
+			getInstanceCPU(task), getTaskCPU(task), getTaskMemory(task))
+		if stopped {
+			fmt.Fprintf(w, "\t%s\t%s\t%s\t%s", formatTime(task.StoppedAt), orNA(task.StopCode),
+				getExitCodes(task), EscapeTags(orNA(task.StoppedReason)))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	return w.Flush()
+}
+
+// EscapeTags protects free form text, such as environment values, from being
+// read as loreley color tags.
+func EscapeTags(s string) string {
+	return strings.Replace(s, "<", `<"<">`, -1)
+}
+
+func writeTaskDefinitionDiff(out io.Writer, from, to TaskDefinition, header func(string) string) error {
+	changes := DiffTaskDefinitions(from, to)
+	fmt.Fprintf(out, "%s -> %s\n\n", from.Name(), to.Name())
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "no differences\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
+	fmt.Fprintf(w, "%s\n", header("Container\tSetting\tFrom\tTo"))
+	for _, c := range changes {
+		container := c.Container
+		if container == "" {
+			container = "(task)"
+		}
+		from, to := c.From, c.To
+		if from == "" {
+			from = "(none)"
+		}
+		if to == "" {
+			to = "(none)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", container, EscapeTags(c.Field), EscapeTags(from), EscapeTags(to))
+	}
+	return w.Flush()
+}
+
 type ServiceOutputer interface {
 	DisplayServices(svcs []Service, w io.Writer) error
 	DisplayTasks(tasks []Task, w io.Writer) error
+	DisplayClusters(clusters []Cluster, w io.Writer) error
+	DisplayInstances(instances []Instance, w io.Writer) error
+	DisplayTaskDetail(task Task, w io.Writer) error
+	DisplayTaskDefinitionDiff(from, to TaskDefinition, w io.Writer) error
 }
 
 type BasicServiceOutputer struct {
@@ -48,52 +390,91 @@ func (o BasicServiceOutputer) truncateARN(arn string, enabled bool) string {
 }
 
 func (o BasicServiceOutputer) DisplayTasks(tasks []Task, out io.Writer) error {
-	w := tabwriter.NewWriter(out, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "Status\tDesired\tTask\tCreated At\tInstance ID\tInstance CPU%%\n")
-	for _, task := range tasks {
-		ca := task.CreatedAt.Format("01-02-2006 15:04")
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", task.Status, task.DesiredStatus,
-			o.truncateARN(task.TaskDefinition, o.shortArns), ca, getInstanceID(task),
-			getInstanceCPU(task))
+	buffer := &bytes.Buffer{}
+	if err := writeTasks(buffer, tasks, basicHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	}); err != nil {
+		return err
 	}
-	return w.Flush()
+	return writeFormatted(buffer, out)
 }
 
 func (o BasicServiceOutputer) DisplayServices(services []Service, out io.Writer) error {
 	buffer := &bytes.Buffer{}
 	w := tabwriter.NewWriter(buffer, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "Name\tRunning\tPending\tTask\tCPU%%\tMemory%%\n")
+	alarms := alarmsLookedUp(services)
+	if alarms {
+		fmt.Fprintf(w, "Name\tRunning\tPending\tTask\tLaunch\tDeploy\tAlarms\tCPU%%\tMemory%%\n")
+	} else {
+		fmt.Fprintf(w, "Name\tRunning\tPending\tTask\tLaunch\tDeploy\tCPU%%\tMemory%%\n")
+	}
 	for _, s := range services {
-		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%f\t%f\n", s.Name, s.RunningCount, s.PendingCount,
-			o.truncateARN(s.TaskDefinition, o.shortArns), s.Metrics.CPU, s.Metrics.Memory)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t", s.Name, s.RunningCount, s.PendingCount,
+			o.truncateARN(s.TaskDefinition, o.shortArns), getServiceLaunchType(s), s.DeployState())
+		if alarms {
+			fmt.Fprintf(w, "%s\t", s.AlarmState())
+		}
+		fmt.Fprintf(w, "%f\t%f\n", s.Metrics.CPU, s.Metrics.Memory)
 	}
 	w.Flush()
 	fmt.Fprintf(w, "\n")
 	w.Flush()
 
-	fmt.Fprintf(w, "Service\tStatus\tDesired\tTask\tCreated At\tInstance ID\tInstance CPU%%\n")
+	writeDeployments(w, services, basicHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	})
+	w.Flush()
+	writeFiringAlarms(w, services, basicHeader)
+	w.Flush()
+
+	fmt.Fprintf(w, "Service\tID\tStatus\tDesired\tTask\tLaunch\tCreated At\tInstance ID\tInstance CPU%%\t"+
+		"Task CPU%%\tTask Memory%%\n")
 	for _, svc := range services {
 		for _, task := range svc.Tasks {
 			ca := task.CreatedAt.Format("01-02-2006 15:04")
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, task.Status, task.DesiredStatus,
-				o.truncateARN(task.TaskDefinition, o.shortArns), ca, getInstanceID(task),
-				getInstanceCPU(task))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, shortTaskID(task.Arn),
+				task.Status, task.DesiredStatus, o.truncateARN(task.TaskDefinition, o.shortArns),
+				getLaunchType(task), ca, getInstanceID(task), getInstanceCPU(task), getTaskCPU(task),
+				getTaskMemory(task))
 		}
 	}
 	w.Flush()
 
-	loreley.DelimLeft = "<"
-	loreley.DelimRight = ">"
-	result, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
-	if err != nil {
-		return fmt.Errorf("error formating output: %s", err.Error())
+	return writeFormatted(buffer, out)
+}
+
+func (o BasicServiceOutputer) DisplayClusters(clusters []Cluster, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeClusters(buffer, clusters, basicHeader); err != nil {
+		return err
 	}
+	return writeFormatted(buffer, out)
+}
 
-	if _, err = out.Write([]byte(result)); err != nil {
-		return fmt.Errorf("error writing output: %s", err.Error())
+func (o BasicServiceOutputer) DisplayInstances(instances []Instance, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeInstances(buffer, instances, basicHeader); err != nil {
+		return err
 	}
+	return writeFormatted(buffer, out)
+}
 
-	return nil
+func (o BasicServiceOutputer) DisplayTaskDetail(task Task, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeTaskDetail(buffer, task, basicHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	}); err != nil {
+		return err
+	}
+	return writeFormatted(buffer, out)
+}
+
+func (o BasicServiceOutputer) DisplayTaskDefinitionDiff(from, to TaskDefinition, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeTaskDefinitionDiff(buffer, from, to, basicHeader); err != nil {
+		return err
+	}
+	return writeFormatted(buffer, out)
 }
 
 type ColorServiceOutputer struct {
@@ -116,60 +497,88 @@ func (o ColorServiceOutputer) truncateARN(arn string, enabled bool) string {
 
 func (o ColorServiceOutputer) DisplayTasks(tasks []Task, out io.Writer) error {
 	buffer := &bytes.Buffer{}
-	w := tabwriter.NewWriter(buffer, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "<fg 13><bold>Status\tDesired\tTask\tCreated At\tInstance ID\tInstance CPU%%<reset>\n")
-	for _, task := range tasks {
-		ca := task.CreatedAt.Format("01-02-2006 15:04")
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", task.Status, task.DesiredStatus,
-			o.truncateARN(task.TaskDefinition, o.shortArns), ca, getInstanceID(task),
-			getInstanceCPU(task))
+	if err := writeTasks(buffer, tasks, colorHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	}); err != nil {
+		return err
 	}
-	w.Flush()
-	loreley.DelimLeft = "<"
-	loreley.DelimRight = ">"
-	result, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
-	if err != nil {
-		return fmt.Errorf("error formating output: %s", err.Error())
-	}
-	if _, err = out.Write([]byte(result)); err != nil {
-		return fmt.Errorf("error writing output: %s", err.Error())
-	}
-	return nil
+	return writeFormatted(buffer, out)
 }
 
 func (o ColorServiceOutputer) DisplayServices(services []Service, out io.Writer) error {
 	buffer := &bytes.Buffer{}
 	w := tabwriter.NewWriter(buffer, 0, 3, 5, ' ', tabwriter.FilterHTML)
-	fmt.Fprintf(w, "<fg 13><bold>Name\tRunning\tPending\tTask\tCPU%%\tMemory%%<reset>\n")
+	alarms := alarmsLookedUp(services)
+	if alarms {
+		fmt.Fprintf(w, "<fg 13><bold>Name\tRunning\tPending\tTask\tLaunch\tDeploy\tAlarms\tCPU%%\tMemory%%<reset>\n")
+	} else {
+		fmt.Fprintf(w, "<fg 13><bold>Name\tRunning\tPending\tTask\tLaunch\tDeploy\tCPU%%\tMemory%%<reset>\n")
+	}
 	for _, s := range services {
-		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%f\t%f\n", s.Name, s.RunningCount, s.PendingCount,
-			o.truncateARN(s.TaskDefinition, o.shortArns), s.Metrics.CPU, s.Metrics.Memory)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t", s.Name, s.RunningCount, s.PendingCount,
+			o.truncateARN(s.TaskDefinition, o.shortArns), getServiceLaunchType(s), colorDeployState(s))
+		if alarms {
+			fmt.Fprintf(w, "%s\t", colorAlarmState(s))
+		}
+		fmt.Fprintf(w, "%f\t%f\n", s.Metrics.CPU, s.Metrics.Memory)
 	}
 	w.Flush()
 	fmt.Fprintf(w, "\n")
 	w.Flush()
 
-	fmt.Fprintf(w, "<fg 13><bold>Service\tStatus\tDesired\tTask\tCreated At\tInstance ID\tInstance CPU%%<reset>\n")
+	writeDeployments(w, services, colorHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	})
+	w.Flush()
+	writeFiringAlarms(w, services, colorHeader)
+	w.Flush()
+
+	fmt.Fprintf(w, "<fg 13><bold>Service\tID\tStatus\tDesired\tTask\tLaunch\tCreated At\tInstance ID\t"+
+		"Instance CPU%%\tTask CPU%%\tTask Memory%%<reset>\n")
 	for _, svc := range services {
 		for _, task := range svc.Tasks {
 			ca := task.CreatedAt.Format("01-02-2006 15:04")
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, task.Status, task.DesiredStatus,
-				o.truncateARN(task.TaskDefinition, o.shortArns), ca, getInstanceID(task),
-				getInstanceCPU(task))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", svc.Name, shortTaskID(task.Arn),
+				task.Status, task.DesiredStatus, o.truncateARN(task.TaskDefinition, o.shortArns),
+				getLaunchType(task), ca, getInstanceID(task), getInstanceCPU(task), getTaskCPU(task),
+				getTaskMemory(task))
 		}
 	}
 	w.Flush()
 
-	loreley.DelimLeft = "<"
-	loreley.DelimRight = ">"
-	result, err := loreley.CompileAndExecuteToString(buffer.String(), nil, nil)
-	if err != nil {
-		return fmt.Errorf("error formating output: %s", err.Error())
+	return writeFormatted(buffer, out)
+}
+
+func (o ColorServiceOutputer) DisplayClusters(clusters []Cluster, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeClusters(buffer, clusters, colorHeader); err != nil {
+		return err
 	}
+	return writeFormatted(buffer, out)
+}
 
-	if _, err = out.Write([]byte(result)); err != nil {
-		return fmt.Errorf("error writing output: %s", err.Error())
+func (o ColorServiceOutputer) DisplayInstances(instances []Instance, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeInstances(buffer, instances, colorHeader); err != nil {
+		return err
 	}
+	return writeFormatted(buffer, out)
+}
 
-	return nil
+func (o ColorServiceOutputer) DisplayTaskDetail(task Task, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeTaskDetail(buffer, task, colorHeader, func(arn string) string {
+		return o.truncateARN(arn, o.shortArns)
+	}); err != nil {
+		return err
+	}
+	return writeFormatted(buffer, out)
+}
+
+func (o ColorServiceOutputer) DisplayTaskDefinitionDiff(from, to TaskDefinition, out io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := writeTaskDefinitionDiff(buffer, from, to, colorHeader); err != nil {
+		return err
+	}
+	return writeFormatted(buffer, out)
 }
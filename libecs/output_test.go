@@ -0,0 +1,263 @@
+package libecs_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func outputers() map[string]libecs.ServiceOutputer {
+	return map[string]libecs.ServiceOutputer{
+		"basic": libecs.NewBasicServiceOutputer(true),
+		"color": libecs.NewColorServiceOutputer(true),
+	}
+}
+
+func assertContains(t *testing.T, out string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDisplayServices(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayServices(services, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "web", "worker", "web:12", "i-0123456789abcdef0",
+				"deploying (2 live)", "PRIMARY", "IN_PROGRESS")
+		})
+	}
+}
+
+func TestDisplayServicesAlarms(t *testing.T) {
+	for _, alarms := range []bool{false, true} {
+		e, _ := newECS(t, libecs.ECSConfig{Alarms: alarms})
+		services, err := e.ListServices()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, o := range outputers() {
+			t.Run(fmt.Sprintf("%s alarms %v", name, alarms), func(t *testing.T) {
+				var out bytes.Buffer
+				if err := o.DisplayServices(services, &out); err != nil {
+					t.Fatal(err)
+				}
+				// The column is left out rather than filled with n/a when
+				// alarms were not looked up.
+				if got := strings.Contains(out.String(), "Alarms"); got != alarms {
+					t.Errorf("output has an Alarms column: %v, want %v:\n%s", got, alarms, out.String())
+				}
+				if alarms {
+					assertContains(t, out.String(), "1 firing", "prod-web-high-cpu")
+				}
+			})
+		}
+	}
+}
+
+func TestDisplayServicesEscapesTags(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	services, err := e.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	services[0].Deployments[0].RolloutState = "FAILED"
+	services[0].Deployments[0].RolloutStateReason = tagLike
+	services[0].Alarms = []libecs.Alarm{{Name: tagLike, State: "ALARM", Reason: tagLike}}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayServices(services, &out); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(out.String(), tagLike); got != 3 {
+				t.Errorf("%q appears %d times, want 3:\n%s", tagLike, got, out.String())
+			}
+		})
+	}
+}
+
+func TestDisplayClusters(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	clusters, err := e.ListClusters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayClusters(clusters, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "Capacity Providers", "prod", "ACTIVE")
+		})
+	}
+}
+
+func TestDisplayInstances(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	instances, err := e.ListContainerInstances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayInstances(instances, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "i-0123456789abcdef0", "i-0fedcba9876543210", "m5.large")
+		})
+	}
+}
+
+func TestDisplayTaskDetail(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	tasks, err := e.ListTasks("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayTaskDetail(tasks[0], &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "0a1b2c3d4e5f40718293a4b5c6d7e8f9", "HEALTHY",
+				"web:v12", "512/1024")
+		})
+	}
+}
+
+// tagLike is free form text that loreley would read as a tag if it were not
+// escaped.
+const tagLike = "<none>"
+
+func TestDisplayTasks(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	tasks, err := e.ListTasksWithStatus("web", libecs.TaskStatusAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayTasks(tasks, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "Stopped Reason", "EssentialContainerExited", "web=1",
+				"Essential container in task exited")
+		})
+	}
+}
+
+func TestDisplayTasksCPU(t *testing.T) {
+	tasks := []libecs.Task{{
+		Arn:             "arn:aws:ecs:us-east-1:123456789012:task/prod/0a1b2c3d4e5f40718293a4b5c6d7e8f9",
+		TaskDefinition:  "arn:aws:ecs:us-east-1:123456789012:task-definition/web:13",
+		InstanceMetrics: &libecs.InstanceMetrics{ID: "i-0123456789abcdef0", CPU: 42.5},
+	}}
+	services := []libecs.Service{{Name: "web", TaskDefinition: tasks[0].TaskDefinition, Tasks: tasks}}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			// Without task metrics the task's own CPU is unknown, and must not
+			// be filled in from its host.
+			for _, display := range []func(*bytes.Buffer) error{
+				func(out *bytes.Buffer) error { return o.DisplayTasks(tasks, out) },
+				func(out *bytes.Buffer) error { return o.DisplayServices(services, out) },
+			} {
+				var out bytes.Buffer
+				if err := display(&out); err != nil {
+					t.Fatal(err)
+				}
+				assertContains(t, out.String(), "Instance CPU%", "Task CPU%", "42.500000%")
+				if got := strings.Count(out.String(), "42.500000%"); got != 1 {
+					t.Errorf("instance CPU appears %d times, want 1:\n%s", got, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDisplayTasksEscapesTags(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	tasks, err := e.ListTasksWithStatus("web", libecs.TaskStatusStopped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks[0].StoppedReason = tagLike
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayTasks(tasks, &out); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(out.String(), tagLike); got != 1 {
+				t.Errorf("%q appears %d times, want 1:\n%s", tagLike, got, out.String())
+			}
+		})
+	}
+}
+
+func TestDisplayTaskDetailEscapesTags(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	tasks, err := e.ListTasks("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := tasks[0]
+	task.StoppedReason = tagLike
+	task.Containers[0].Reason = tagLike
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayTaskDetail(task, &out); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(out.String(), tagLike); got != 2 {
+				t.Errorf("%q appears %d times, want 2:\n%s", tagLike, got, out.String())
+			}
+		})
+	}
+}
+
+func TestDisplayTaskDefinitionDiff(t *testing.T) {
+	from := libecs.TaskDefinition{Family: "web", Revision: 12, Containers: []libecs.ContainerDefinition{
+		{Name: "web", Image: "web:v12", Environment: map[string]string{"GREETING": "hello"}},
+	}}
+	to := libecs.TaskDefinition{Family: "web", Revision: 13, Containers: []libecs.ContainerDefinition{
+		{Name: "web", Image: "web:v13", Environment: map[string]string{"GREETING": tagLike}},
+	}}
+	for name, o := range outputers() {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := o.DisplayTaskDefinitionDiff(from, to, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "web:12 -> web:13", "web:v13", "env[GREETING]")
+			if got := strings.Count(out.String(), tagLike); got != 1 {
+				t.Errorf("%q appears %d times, want 1:\n%s", tagLike, got, out.String())
+			}
+
+			out.Reset()
+			if err := o.DisplayTaskDefinitionDiff(from, from, &out); err != nil {
+				t.Fatal(err)
+			}
+			assertContains(t, out.String(), "no differences")
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package libecs
+
+import "sync"
+
+const defaultConcurrency = 8
+
+func (e *ECS) concurrency() int {
+	if e.config.Concurrency > 0 {
+		return e.config.Concurrency
+	}
+	return defaultConcurrency
+}
+
+// forEach calls fn for every index in [0, n) using at most limit goroutines.
+// Callers write results into a pre-sized slice by index, so output order does
+// not depend on scheduling. The first error is returned, and indexes not yet
+// started when it occurs are skipped.
+func forEach(n, limit int, fn func(i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > n {
+		limit = n
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	indexCh := make(chan int)
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexCh {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		indexCh <- i
+	}
+	close(indexCh)
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,72 @@
+package libecs
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEach(t *testing.T) {
+	cases := []struct {
+		n, limit int
+	}{
+		{0, 8},
+		{1, 8},
+		{20, 1},
+		{20, 4},
+		{20, 0},
+		{5, 100},
+	}
+	for _, c := range cases {
+		var mu sync.Mutex
+		seen := make([]int, c.n)
+		var inFlight, maxInFlight int32
+		err := forEach(c.n, c.limit, func(i int) error {
+			cur := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			mu.Lock()
+			if cur > maxInFlight {
+				maxInFlight = cur
+			}
+			seen[i]++
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("forEach(%d, %d): %s", c.n, c.limit, err)
+		}
+		for i, count := range seen {
+			if count != 1 {
+				t.Errorf("forEach(%d, %d) called index %d %d times", c.n, c.limit, i, count)
+			}
+		}
+		limit := c.limit
+		if limit < 1 {
+			limit = 1
+		}
+		if int(maxInFlight) > limit {
+			t.Errorf("forEach(%d, %d) ran %d calls at once", c.n, c.limit, maxInFlight)
+		}
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	failure := errors.New("failed")
+	var calls int32
+	err := forEach(1000, 2, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i == 3 {
+			return failure
+		}
+		return nil
+	})
+	if err != failure {
+		t.Errorf("forEach error = %v, want %v", err, failure)
+	}
+	if calls == 1000 {
+		t.Errorf("forEach kept going after an error")
+	}
+}
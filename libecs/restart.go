@@ -0,0 +1,89 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// RestartService forces a new deployment of the service's current task
+// definition, replacing all of its tasks. It returns the new deployment; use
+// WaitForDeployment to follow it.
+func (e *ECS) RestartService(name string) (Deployment, error) {
+	return e.RestartServiceWithContext(context.Background(), name)
+}
+
+func (e *ECS) RestartServiceWithContext(ctx context.Context, name string) (Deployment, error) {
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.UpdateServiceWithContext(cctx, &ecs.UpdateServiceInput{
+		Cluster:            aws.String(e.cluster()),
+		Service:            aws.String(name),
+		ForceNewDeployment: aws.Bool(true),
+	})
+	if err != nil {
+		return Deployment{}, err
+	}
+	primary, ok := newService(resp.Service).PrimaryDeployment()
+	if !ok {
+		return Deployment{}, fmt.Errorf("no primary deployment after restarting %s", name)
+	}
+	return primary, nil
+}
+
+// deploymentDone reports whether a deployment has finished rolling out, and
+// returns an error if it failed. Services without a deployment circuit
+// breaker report no rollout state, so fall back to the task counts.
+func deploymentDone(svc Service, d Deployment) (bool, error) {
+	switch d.RolloutState {
+	case ecs.DeploymentRolloutStateFailed:
+		return false, fmt.Errorf("deployment %s failed: %s", d.ID, d.RolloutStateReason)
+	case ecs.DeploymentRolloutStateCompleted:
+		return true, nil
+	case "":
+		return steady(svc, d.DesiredCount), nil
+	}
+	return false, nil
+}
+
+// WaitForDeployment polls the service until the given deployment completes,
+// fails, is replaced, or the timeout passes. If progress is not nil it is
+// called with each observed state of the deployment.
+func (e *ECS) WaitForDeployment(name, id string, timeout time.Duration, progress func(Deployment)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return e.WaitForDeploymentWithContext(ctx, name, id, progress)
+}
+
+func (e *ECS) WaitForDeploymentWithContext(ctx context.Context, name, id string, progress func(Deployment)) error {
+	for {
+		svc, err := e.describeService(ctx, name)
+		if err != nil {
+			return err
+		}
+		s := newService(svc)
+		primary, ok := s.PrimaryDeployment()
+		if !ok || primary.ID != id {
+			return fmt.Errorf("deployment %s of %s was replaced by another deployment", id, name)
+		}
+		if progress != nil {
+			progress(primary)
+		}
+		done, err := deploymentDone(s, primary)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("deployment %s of %s did not complete: %s", id, name, ctx.Err())
+		case <-time.After(e.pollInterval()):
+		}
+	}
+}
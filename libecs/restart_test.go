@@ -0,0 +1,71 @@
+package libecs_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestRestartService(t *testing.T) {
+	cases := []struct {
+		name    string
+		fail    bool
+		replace bool
+		poll    time.Duration
+		wantErr string
+	}{
+		{name: "completed", poll: time.Millisecond},
+		{name: "failed", fail: true, poll: time.Millisecond,
+			wantErr: "failed: ECS deployment circuit breaker: tasks failed to start."},
+		{name: "replaced", replace: true, poll: time.Millisecond, wantErr: "was replaced by another deployment"},
+		{name: "timed out", poll: time.Hour, wantErr: "did not complete"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, b := newECS(t, libecs.ECSConfig{PollInterval: c.poll})
+			b.FailDeployments = c.fail
+			before, err := e.ListServices()
+			if err != nil {
+				t.Fatal(err)
+			}
+			old, _ := before[0].PrimaryDeployment()
+
+			d, err := e.RestartService("web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.ID == old.ID || d.Status != "PRIMARY" || d.RolloutState != "IN_PROGRESS" {
+				t.Errorf("RestartService = %+v, want a new in-progress primary deployment", d)
+			}
+			if c.replace {
+				if _, err := e.RestartService("web"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var last libecs.Deployment
+			err = e.WaitForDeployment("web", d.ID, 20*time.Millisecond, func(d libecs.Deployment) {
+				last = d
+			})
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if last.RolloutState != "COMPLETED" || last.RunningCount != 2 {
+					t.Errorf("last observed deployment = %+v, want completed with 2 running", last)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("WaitForDeployment error = %v, want one containing %q", err, c.wantErr)
+			}
+		})
+	}
+
+	e, _ := newECS(t, libecs.ECSConfig{})
+	if _, err := e.RestartService("missing"); err == nil {
+		t.Errorf("RestartService of a missing service succeeded")
+	}
+}
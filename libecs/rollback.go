@@ -0,0 +1,97 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+type Rollback struct {
+	Service string
+	From    TaskDefinition
+	To      TaskDefinition
+}
+
+// PlanRollback works out which task definition RollbackService would move
+// the service to, without changing anything. revision may be empty for the
+// previous active revision, a bare revision number in the service's family,
+// or a full task definition name or ARN.
+func (e *ECS) PlanRollback(name, revision string) (Rollback, error) {
+	return e.PlanRollbackWithContext(context.Background(), name, revision)
+}
+
+func (e *ECS) PlanRollbackWithContext(ctx context.Context, name, revision string) (Rollback, error) {
+	current, err := e.ServiceTaskDefinitionWithContext(ctx, name)
+	if err != nil {
+		return Rollback{}, err
+	}
+	var target TaskDefinition
+	if revision == "" {
+		target, err = e.PreviousTaskDefinitionWithContext(ctx, current.Arn)
+	} else {
+		if _, err := strconv.Atoi(revision); err == nil {
+			revision = fmt.Sprintf("%s:%s", current.Family, revision)
+		}
+		target, err = e.DescribeTaskDefinitionWithContext(ctx, revision)
+	}
+	if err != nil {
+		return Rollback{}, err
+	}
+	if target.Family != current.Family {
+		return Rollback{}, fmt.Errorf("%s is not in the service's task definition family %s",
+			target.Name(), current.Family)
+	}
+	if target.Status != ecs.TaskDefinitionStatusActive {
+		return Rollback{}, fmt.Errorf("%s is %s and cannot be deployed", target.Name(), target.Status)
+	}
+	if target.Arn == current.Arn {
+		return Rollback{}, fmt.Errorf("%s is already running %s", name, current.Name())
+	}
+	return Rollback{
+		Service: name,
+		From:    current,
+		To:      target,
+	}, nil
+}
+
+// RollbackService moves the service to an earlier task definition, chosen as
+// in PlanRollback, and waits for the resulting deployment to complete. If
+// progress is not nil it is called with each observed state of the
+// deployment.
+func (e *ECS) RollbackService(name, revision string, timeout time.Duration,
+	progress func(Deployment)) (Rollback, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return e.RollbackServiceWithContext(ctx, name, revision, progress)
+}
+
+func (e *ECS) RollbackServiceWithContext(ctx context.Context, name, revision string,
+	progress func(Deployment)) (Rollback, error) {
+	rollback, err := e.PlanRollbackWithContext(ctx, name, revision)
+	if err != nil {
+		return Rollback{}, err
+	}
+
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.UpdateServiceWithContext(cctx, &ecs.UpdateServiceInput{
+		Cluster:        aws.String(e.cluster()),
+		Service:        aws.String(name),
+		TaskDefinition: aws.String(rollback.To.Arn),
+	})
+	if err != nil {
+		return Rollback{}, err
+	}
+	primary, ok := newService(resp.Service).PrimaryDeployment()
+	if !ok {
+		return Rollback{}, fmt.Errorf("no primary deployment after rolling back %s", name)
+	}
+	if err := e.WaitForDeploymentWithContext(ctx, name, primary.ID, progress); err != nil {
+		return rollback, err
+	}
+	return rollback, nil
+}
@@ -0,0 +1,74 @@
+package libecs_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestPlanRollback(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		service  string
+		revision string
+		want     string
+		wantErr  string
+	}{
+		{service: "web", revision: "", want: "web:12"},
+		{service: "web", revision: "12", want: "web:12"},
+		{service: "web", revision: "web:12", want: "web:12"},
+		{service: "web", revision: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:12", want: "web:12"},
+		{service: "worker", revision: "", want: "worker:2"},
+		{service: "web", revision: "11", wantErr: "web:11 is INACTIVE"},
+		{service: "web", revision: "13", wantErr: "already running web:13"},
+		{service: "web", revision: "worker:3", wantErr: "not in the service's task definition family web"},
+		{service: "api", revision: "", wantErr: "no active revision of api older than 4"},
+	}
+	for _, c := range cases {
+		t.Run(c.service+"@"+c.revision, func(t *testing.T) {
+			plan, err := e.PlanRollback(c.service, c.revision)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Errorf("PlanRollback() error = %v, want one containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Service != c.service || plan.To.Name() != c.want {
+				t.Errorf("PlanRollback() = %s to %s, want %s to %s", plan.Service, plan.To.Name(),
+					c.service, c.want)
+			}
+		})
+	}
+	if calls := b.Calls("UpdateService"); calls != 0 {
+		t.Errorf("PlanRollback called UpdateService %d times", calls)
+	}
+}
+
+func TestRollbackService(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{PollInterval: time.Millisecond})
+	var last libecs.Deployment
+	rollback, err := e.RollbackService("worker", "", time.Minute, func(d libecs.Deployment) {
+		last = d
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rollback.From.Name() != "worker:3" || rollback.To.Name() != "worker:2" {
+		t.Errorf("RollbackService() = %s to %s, want worker:3 to worker:2", rollback.From.Name(),
+			rollback.To.Name())
+	}
+	if last.TaskDefinition != rollback.To.Arn || last.RolloutState != "COMPLETED" {
+		t.Errorf("last observed deployment = %+v, want a completed rollout of worker:2", last)
+	}
+
+	b.FailDeployments = true
+	if _, err := e.RollbackService("web", "12", time.Minute, nil); err == nil ||
+		!strings.Contains(err.Error(), "circuit breaker") {
+		t.Errorf("RollbackService() error = %v, want the deployment failure", err)
+	}
+}
@@ -0,0 +1,121 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const defaultPollInterval = 15 * time.Second
+
+func (e *ECS) pollInterval() time.Duration {
+	if e.config.PollInterval > 0 {
+		return e.config.PollInterval
+	}
+	return defaultPollInterval
+}
+
+type ScaleChange struct {
+	Service string
+	From    int
+	To      int
+	Running int
+}
+
+func (e *ECS) checkDesiredCount(desired int) error {
+	if desired < 0 {
+		return fmt.Errorf("desired count %d is negative", desired)
+	}
+	if desired < e.config.MinDesiredCount {
+		return fmt.Errorf("desired count %d is below the minimum of %d", desired, e.config.MinDesiredCount)
+	}
+	if e.config.MaxDesiredCount > 0 && desired > e.config.MaxDesiredCount {
+		return fmt.Errorf("desired count %d is above the maximum of %d", desired, e.config.MaxDesiredCount)
+	}
+	return nil
+}
+
+// PlanScale validates a scaling request and reports what ScaleService would
+// change, without changing anything.
+func (e *ECS) PlanScale(name string, desired int) (ScaleChange, error) {
+	return e.PlanScaleWithContext(context.Background(), name, desired)
+}
+
+func (e *ECS) PlanScaleWithContext(ctx context.Context, name string, desired int) (ScaleChange, error) {
+	if err := e.checkDesiredCount(desired); err != nil {
+		return ScaleChange{}, err
+	}
+	svc, err := e.describeService(ctx, name)
+	if err != nil {
+		return ScaleChange{}, err
+	}
+	return ScaleChange{
+		Service: aws.StringValue(svc.ServiceName),
+		From:    int(aws.Int64Value(svc.DesiredCount)),
+		To:      desired,
+		Running: int(aws.Int64Value(svc.RunningCount)),
+	}, nil
+}
+
+// ScaleService sets the service's desired count, which must be within
+// ECSConfig.MinDesiredCount and MaxDesiredCount. It returns once ECS accepts
+// the change; use WaitForSteadyState to wait for the tasks.
+func (e *ECS) ScaleService(name string, desired int) (ScaleChange, error) {
+	return e.ScaleServiceWithContext(context.Background(), name, desired)
+}
+
+func (e *ECS) ScaleServiceWithContext(ctx context.Context, name string, desired int) (ScaleChange, error) {
+	change, err := e.PlanScaleWithContext(ctx, name, desired)
+	if err != nil {
+		return ScaleChange{}, err
+	}
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	if _, err := e.ecs.UpdateServiceWithContext(cctx, &ecs.UpdateServiceInput{
+		Cluster:      aws.String(e.cluster()),
+		Service:      aws.String(name),
+		DesiredCount: aws.Int64(int64(desired)),
+	}); err != nil {
+		return ScaleChange{}, err
+	}
+	return change, nil
+}
+
+// steady reports whether a service has settled: a single deployment with all
+// of its desired tasks running.
+func steady(svc Service, desired int) bool {
+	return len(svc.Deployments) == 1 && svc.RunningCount == desired && svc.PendingCount == 0
+}
+
+// WaitForSteadyState polls the service until it settles or the timeout
+// passes. If progress is not nil it is called with each observed state.
+func (e *ECS) WaitForSteadyState(name string, timeout time.Duration, progress func(Service)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return e.WaitForSteadyStateWithContext(ctx, name, progress)
+}
+
+func (e *ECS) WaitForSteadyStateWithContext(ctx context.Context, name string, progress func(Service)) error {
+	for {
+		svc, err := e.describeService(ctx, name)
+		if err != nil {
+			return err
+		}
+		s := newService(svc)
+		if progress != nil {
+			progress(s)
+		}
+		if steady(s, int(aws.Int64Value(svc.DesiredCount))) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("service %s did not reach a steady state: %s", name, ctx.Err())
+		case <-time.After(e.pollInterval()):
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package libecs_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestPlanScale(t *testing.T) {
+	cases := []struct {
+		name     string
+		min, max int
+		desired  int
+		wantErr  string
+	}{
+		{name: "unbounded", desired: 500},
+		{name: "to zero", desired: 0},
+		{name: "negative", desired: -1, wantErr: "desired count -1 is negative"},
+		{name: "negative with no minimum", max: 10, desired: -1, wantErr: "is negative"},
+		{name: "at minimum", min: 1, max: 10, desired: 1},
+		{name: "below minimum", min: 1, max: 10, desired: 0, wantErr: "below the minimum of 1"},
+		{name: "at maximum", min: 1, max: 10, desired: 10},
+		{name: "above maximum", min: 1, max: 10, desired: 11, wantErr: "above the maximum of 10"},
+		{name: "no maximum", min: 1, desired: 1000},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, b := newECS(t, libecs.ECSConfig{MinDesiredCount: c.min, MaxDesiredCount: c.max})
+			change, err := e.PlanScale("web", c.desired)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Errorf("PlanScale(%d) error = %v, want one containing %q", c.desired, err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := libecs.ScaleChange{Service: "web", From: 2, To: c.desired, Running: 2}
+			if change != want {
+				t.Errorf("PlanScale(%d) = %+v, want %+v", c.desired, change, want)
+			}
+			if calls := b.Calls("UpdateService"); calls != 0 {
+				t.Errorf("PlanScale called UpdateService %d times", calls)
+			}
+		})
+	}
+
+	e, _ := newECS(t, libecs.ECSConfig{})
+	if _, err := e.PlanScale("missing", 1); err == nil {
+		t.Errorf("PlanScale of a missing service succeeded")
+	}
+}
+
+func TestScaleService(t *testing.T) {
+	e, b := newECS(t, libecs.ECSConfig{PollInterval: time.Millisecond})
+	change, err := e.ScaleService("worker", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.From != 1 || change.To != 3 {
+		t.Errorf("ScaleService = %+v, want 1 to 3", change)
+	}
+	if calls := b.Calls("UpdateService"); calls != 1 {
+		t.Errorf("UpdateService calls = %d, want 1", calls)
+	}
+
+	var seen []int
+	if err := e.WaitForSteadyState("worker", time.Minute, func(s libecs.Service) {
+		seen = append(seen, s.RunningCount)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) == 0 || seen[len(seen)-1] != 3 {
+		t.Errorf("running counts seen = %v, want to end at 3", seen)
+	}
+
+	if _, err := e.ScaleService("worker", -1); err == nil {
+		t.Errorf("ScaleService to -1 succeeded")
+	}
+	if calls := b.Calls("UpdateService"); calls != 1 {
+		t.Errorf("UpdateService called for a rejected count")
+	}
+}
+
+func TestWaitForSteadyStateTimeout(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{PollInterval: time.Hour})
+	if _, err := e.ScaleService("worker", 5); err != nil {
+		t.Fatal(err)
+	}
+	err := e.WaitForSteadyState("worker", 10*time.Millisecond, nil)
+	if err == nil || !strings.Contains(err.Error(), "did not reach a steady state") {
+		t.Errorf("WaitForSteadyState error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,132 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+type MetricPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// MetricSeries is the data behind one line of a graph. Points are sorted by
+// time, and periods without data are left out.
+type MetricSeries struct {
+	Label      string
+	Namespace  string
+	Name       string
+	Dimensions map[string]string
+	Stat       string
+	Period     time.Duration
+	Points     []MetricPoint
+}
+
+// GetMetricSeries fetches the datapoints a graph of the spec would show, one
+// series per line, in the order GetGraph would draw them.
+func (e *ECS) GetMetricSeries(spec GraphSpec, opts GraphOptions) ([]MetricSeries, error) {
+	return e.GetMetricSeriesWithContext(context.Background(), spec, opts)
+}
+
+func (e *ECS) GetMetricSeriesWithContext(ctx context.Context, spec GraphSpec,
+	opts GraphOptions) ([]MetricSeries, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	start, end, period, err := opts.window(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var res []MetricSeries
+	var queries []*cloudwatch.MetricDataQuery
+	for _, m := range spec.Metrics {
+		for _, line := range e.graphLines(m) {
+			queries = append(queries, metricStatQuery(fmt.Sprintf("m%d", len(res)+1), line.namespace,
+				line.name, line.dims, line.stat, int64(period/time.Second)))
+			res = append(res, MetricSeries{
+				Label:      line.label,
+				Namespace:  line.namespace,
+				Name:       line.name,
+				Dimensions: line.dims,
+				Stat:       line.stat,
+				Period:     period,
+			})
+		}
+	}
+	index := make(map[string]int, len(queries))
+	for i, q := range queries {
+		index[aws.StringValue(q.Id)] = i
+	}
+
+	numBatches := (len(queries) + metricDataBatchSize - 1) / metricDataBatchSize
+	// Each batch only touches its own queries' series, so they can be filled
+	// in concurrently
+	err = forEach(numBatches, e.concurrency(), func(b int) error {
+		lim := (b + 1) * metricDataBatchSize
+		if lim > len(queries) {
+			lim = len(queries)
+		}
+		ctx, cancel := e.callContext(ctx)
+		defer cancel()
+		return e.cloudwatch.GetMetricDataPagesWithContext(ctx, &cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries[b*metricDataBatchSize : lim],
+			StartTime:         aws.Time(start),
+			EndTime:           aws.Time(end),
+			ScanBy:            aws.String(cloudwatch.ScanByTimestampAscending),
+		}, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, r := range page.MetricDataResults {
+				i, ok := index[aws.StringValue(r.Id)]
+				if !ok {
+					continue
+				}
+				for j := range r.Values {
+					if j >= len(r.Timestamps) {
+						break
+					}
+					res[i].Points = append(res[i].Points, MetricPoint{
+						Time:  aws.TimeValue(r.Timestamps[j]),
+						Value: aws.Float64Value(r.Values[j]),
+					})
+				}
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range res {
+		points := s.Points
+		sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	}
+	return res, nil
+}
+
+// ServiceMetricSeries fetches one of the service's AWS/ECS metrics, such as
+// CPUUtilization, with a series per statistic. A zero period is picked from
+// the window, and no stats means Average.
+func (e *ECS) ServiceMetricSeries(service, metric string, window TimeRange, period time.Duration,
+	stats []string) ([]MetricSeries, error) {
+	return e.ServiceMetricSeriesWithContext(context.Background(), service, metric, window, period, stats)
+}
+
+func (e *ECS) ServiceMetricSeriesWithContext(ctx context.Context, service, metric string, window TimeRange,
+	period time.Duration, stats []string) ([]MetricSeries, error) {
+	return e.GetMetricSeriesWithContext(ctx, GraphSpec{
+		Metrics: []GraphMetric{{
+			Namespace: "AWS/ECS",
+			Name:      metric,
+			Stats:     stats,
+			Services:  []string{service},
+		}},
+	}, GraphOptions{
+		Range:  window,
+		Period: period,
+	})
+}
@@ -0,0 +1,60 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// ResolveTask looks up a task in the cluster by its full ARN or by its task
+// ID, the last component of the ARN.
+func (e *ECS) ResolveTask(id string) (Task, error) {
+	return e.ResolveTaskWithContext(context.Background(), id)
+}
+
+func (e *ECS) ResolveTaskWithContext(ctx context.Context, id string) (Task, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.DescribeTasksWithContext(ctx, &ecs.DescribeTasksInput{
+		Cluster: aws.String(e.cluster()),
+		Tasks:   []*string{aws.String(id)},
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	if len(resp.Tasks) == 0 {
+		var reasons []string
+		for _, f := range resp.Failures {
+			reasons = append(reasons, aws.StringValue(f.Reason))
+		}
+		return Task{}, fmt.Errorf("task not found in %s: %s (%s)", e.cluster(), id,
+			strings.Join(reasons, ", "))
+	}
+	return newTask(resp.Tasks[0]), nil
+}
+
+// StopTask stops a task, given by ARN or task ID. The reason is kept by ECS
+// as the task's stopped reason.
+func (e *ECS) StopTask(arn, reason string) (Task, error) {
+	return e.StopTaskWithContext(context.Background(), arn, reason)
+}
+
+func (e *ECS) StopTaskWithContext(ctx context.Context, arn, reason string) (Task, error) {
+	if reason == "" {
+		return Task{}, fmt.Errorf("a reason is required to stop a task")
+	}
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.StopTaskWithContext(ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(e.cluster()),
+		Task:    aws.String(arn),
+		Reason:  aws.String(reason),
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return newTask(resp.Task), nil
+}
@@ -0,0 +1,206 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+type TaskDefinition struct {
+	Arn      string
+	Family   string
+	Revision int
+	Status   string
+	// CPU and Memory are the task level sizes, which are empty for EC2 task
+	// definitions that only size their containers.
+	CPU              string
+	Memory           string
+	NetworkMode      string
+	TaskRoleArn      string
+	ExecutionRoleArn string
+	Containers       []ContainerDefinition
+	RegisteredAt     time.Time
+}
+
+// Name is the family:revision form used to refer to the task definition.
+func (t TaskDefinition) Name() string {
+	return fmt.Sprintf("%s:%d", t.Family, t.Revision)
+}
+
+type ContainerDefinition struct {
+	Name              string
+	Image             string
+	CPU               int
+	Memory            int
+	MemoryReservation int
+	Essential         bool
+	Command           []string
+	EntryPoint        []string
+	Environment       map[string]string
+	// Secrets maps each environment variable to the secret it is read from.
+	Secrets      map[string]string
+	PortMappings []PortMapping
+	LogDriver    string
+	LogOptions   map[string]string
+}
+
+type PortMapping struct {
+	ContainerPort int
+	HostPort      int
+	Protocol      string
+}
+
+func (p PortMapping) String() string {
+	return fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol)
+}
+
+func newContainerDefinition(c *ecs.ContainerDefinition) ContainerDefinition {
+	res := ContainerDefinition{
+		Name:              aws.StringValue(c.Name),
+		Image:             aws.StringValue(c.Image),
+		CPU:               int(aws.Int64Value(c.Cpu)),
+		Memory:            int(aws.Int64Value(c.Memory)),
+		MemoryReservation: int(aws.Int64Value(c.MemoryReservation)),
+		Essential:         aws.BoolValue(c.Essential),
+		Command:           aws.StringValueSlice(c.Command),
+		EntryPoint:        aws.StringValueSlice(c.EntryPoint),
+		Environment:       make(map[string]string),
+		Secrets:           make(map[string]string),
+		LogOptions:        make(map[string]string),
+	}
+	for _, kv := range c.Environment {
+		res.Environment[aws.StringValue(kv.Name)] = aws.StringValue(kv.Value)
+	}
+	for _, secret := range c.Secrets {
+		res.Secrets[aws.StringValue(secret.Name)] = aws.StringValue(secret.ValueFrom)
+	}
+	for _, pm := range c.PortMappings {
+		protocol := aws.StringValue(pm.Protocol)
+		if protocol == "" {
+			protocol = ecs.TransportProtocolTcp
+		}
+		res.PortMappings = append(res.PortMappings, PortMapping{
+			ContainerPort: int(aws.Int64Value(pm.ContainerPort)),
+			HostPort:      int(aws.Int64Value(pm.HostPort)),
+			Protocol:      protocol,
+		})
+	}
+	if c.LogConfiguration != nil {
+		res.LogDriver = aws.StringValue(c.LogConfiguration.LogDriver)
+		res.LogOptions = aws.StringValueMap(c.LogConfiguration.Options)
+	}
+	return res
+}
+
+func newTaskDefinition(td *ecs.TaskDefinition) TaskDefinition {
+	res := TaskDefinition{
+		Arn:              aws.StringValue(td.TaskDefinitionArn),
+		Family:           aws.StringValue(td.Family),
+		Revision:         int(aws.Int64Value(td.Revision)),
+		Status:           aws.StringValue(td.Status),
+		CPU:              aws.StringValue(td.Cpu),
+		Memory:           aws.StringValue(td.Memory),
+		NetworkMode:      aws.StringValue(td.NetworkMode),
+		TaskRoleArn:      aws.StringValue(td.TaskRoleArn),
+		ExecutionRoleArn: aws.StringValue(td.ExecutionRoleArn),
+		RegisteredAt:     aws.TimeValue(td.RegisteredAt).Local(),
+	}
+	for _, c := range td.ContainerDefinitions {
+		res.Containers = append(res.Containers, newContainerDefinition(c))
+	}
+	return res
+}
+
+// parseTaskDefinition splits a task definition ARN or family:revision string
+// into its family and revision.
+func parseTaskDefinition(name string) (string, int, error) {
+	toks := strings.Split(name, "/")
+	familyRev := toks[len(toks)-1]
+	idx := strings.LastIndex(familyRev, ":")
+	if idx < 0 {
+		return "", 0, fmt.Errorf("task definition has no revision: %s", name)
+	}
+	rev, err := strconv.Atoi(familyRev[idx+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid task definition revision: %s", name)
+	}
+	return familyRev[:idx], rev, nil
+}
+
+// DescribeTaskDefinition looks up a task definition by ARN, family:revision,
+// or family alone for the latest active revision.
+func (e *ECS) DescribeTaskDefinition(name string) (TaskDefinition, error) {
+	return e.DescribeTaskDefinitionWithContext(context.Background(), name)
+}
+
+func (e *ECS) DescribeTaskDefinitionWithContext(ctx context.Context, name string) (TaskDefinition, error) {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	resp, err := e.ecs.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(name),
+	})
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+	return newTaskDefinition(resp.TaskDefinition), nil
+}
+
+// PreviousTaskDefinition returns the newest active revision in the task
+// definition's family that is older than it. Deregistered revisions are
+// skipped.
+func (e *ECS) PreviousTaskDefinition(name string) (TaskDefinition, error) {
+	return e.PreviousTaskDefinitionWithContext(context.Background(), name)
+}
+
+func (e *ECS) PreviousTaskDefinitionWithContext(ctx context.Context, name string) (TaskDefinition, error) {
+	family, rev, err := parseTaskDefinition(name)
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+
+	cctx, cancel := e.callContext(ctx)
+	defer cancel()
+	var prev string
+	err = e.ecs.ListTaskDefinitionsPagesWithContext(cctx, &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       aws.String(ecs.TaskDefinitionStatusActive),
+		Sort:         aws.String(ecs.SortOrderDesc),
+	}, func(page *ecs.ListTaskDefinitionsOutput, lastPage bool) bool {
+		for _, arn := range aws.StringValueSlice(page.TaskDefinitionArns) {
+			// The prefix match can pick up other families, e.g. web-worker
+			// for web
+			f, r, err := parseTaskDefinition(arn)
+			if err == nil && f == family && r < rev {
+				prev = arn
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+	if prev == "" {
+		return TaskDefinition{}, fmt.Errorf("no active revision of %s older than %d", family, rev)
+	}
+	return e.DescribeTaskDefinitionWithContext(ctx, prev)
+}
+
+// ServiceTaskDefinition returns the task definition the service is currently
+// set to run.
+func (e *ECS) ServiceTaskDefinition(service string) (TaskDefinition, error) {
+	return e.ServiceTaskDefinitionWithContext(context.Background(), service)
+}
+
+func (e *ECS) ServiceTaskDefinitionWithContext(ctx context.Context, service string) (TaskDefinition, error) {
+	svc, err := e.describeService(ctx, service)
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+	return e.DescribeTaskDefinitionWithContext(ctx, aws.StringValue(svc.TaskDefinition))
+}
@@ -0,0 +1,104 @@
+package libecs_test
+
+import (
+	"testing"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestDescribeTaskDefinition(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "web:12", want: "web:12"},
+		{name: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:11", want: "web:11"},
+		// A bare family is its latest active revision.
+		{name: "web", want: "web:13"},
+		{name: "web:99", wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			td, err := e.DescribeTaskDefinition(c.name)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("DescribeTaskDefinition(%q) = %s, want an error", c.name, td.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if td.Name() != c.want {
+				t.Errorf("DescribeTaskDefinition(%q) = %s, want %s", c.name, td.Name(), c.want)
+			}
+		})
+	}
+}
+
+func TestPreviousTaskDefinition(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	cases := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "web:13", want: "web:12"},
+		{name: "worker:3", want: "worker:2"},
+		// web:11 is inactive, so nothing precedes web:12.
+		{name: "web:12", wantErr: true},
+		{name: "web", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			td, err := e.PreviousTaskDefinition(c.name)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("PreviousTaskDefinition(%q) = %s, want an error", c.name, td.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if td.Name() != c.want {
+				t.Errorf("PreviousTaskDefinition(%q) = %s, want %s", c.name, td.Name(), c.want)
+			}
+		})
+	}
+}
+
+func TestServiceTaskDefinitionDiff(t *testing.T) {
+	e, _ := newECS(t, libecs.ECSConfig{})
+	to, err := e.ServiceTaskDefinition("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to.Name() != "web:13" {
+		t.Fatalf("web runs %s, want web:13", to.Name())
+	}
+	from, err := e.PreviousTaskDefinition(to.Arn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := libecs.DiffTaskDefinitions(from, to)
+	fields := make(map[string]libecs.TaskDefinitionChange)
+	for _, c := range changes {
+		fields[c.Field] = c
+	}
+	if c := fields["image"]; c.Container != "web" || c.To != "123456789012.dkr.ecr.us-east-1.amazonaws.com/web:v13" {
+		t.Errorf("image change = %+v", c)
+	}
+	if c := fields["env[LOG_LEVEL]"]; c.From != "info" || c.To != "debug" {
+		t.Errorf("LOG_LEVEL change = %+v", c)
+	}
+	if c := fields["env[FEATURE_FLAGS]"]; c.From != "" || c.To != "checkout-v2" {
+		t.Errorf("FEATURE_FLAGS change = %+v", c)
+	}
+	if _, ok := fields["env[WORKERS]"]; ok {
+		t.Errorf("unchanged WORKERS reported as changed")
+	}
+}
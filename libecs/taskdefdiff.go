@@ -0,0 +1,148 @@
+package libecs
+
+import (
+	"strconv"
+	"strings"
+)
+
+type TaskDefinitionChange struct {
+	// Container is empty for task level settings.
+	Container string
+	Field     string
+	// From and To are empty when the setting was added or removed.
+	From string
+	To   string
+}
+
+type taskDefDiff struct {
+	container string
+	changes   []TaskDefinitionChange
+}
+
+func (d *taskDefDiff) add(field, from, to string) {
+	if from != to {
+		d.changes = append(d.changes, TaskDefinitionChange{
+			Container: d.container,
+			Field:     field,
+			From:      from,
+			To:        to,
+		})
+	}
+}
+
+func (d *taskDefDiff) addInt(field string, from, to int) {
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	d.add(field, itoa(from), itoa(to))
+}
+
+func (d *taskDefDiff) addMap(field string, from, to map[string]string) {
+	keys := make(map[string]string, len(from)+len(to))
+	for k, v := range from {
+		keys[k] = v
+	}
+	for k, v := range to {
+		keys[k] = v
+	}
+	for _, k := range sortedKeys(keys) {
+		d.add(field+"["+k+"]", from[k], to[k])
+	}
+}
+
+func portMap(ports []PortMapping) map[string]string {
+	res := make(map[string]string, len(ports))
+	for _, p := range ports {
+		if p.HostPort == 0 {
+			res[p.String()] = "dynamic host port"
+		} else {
+			res[p.String()] = "host port " + strconv.Itoa(p.HostPort)
+		}
+	}
+	return res
+}
+
+func (d *taskDefDiff) diffContainers(from, to ContainerDefinition) {
+	d.add("image", from.Image, to.Image)
+	d.addInt("cpu", from.CPU, to.CPU)
+	d.addInt("memory", from.Memory, to.Memory)
+	d.addInt("memoryReservation", from.MemoryReservation, to.MemoryReservation)
+	d.add("essential", strconv.FormatBool(from.Essential), strconv.FormatBool(to.Essential))
+	d.add("command", strings.Join(from.Command, " "), strings.Join(to.Command, " "))
+	d.add("entryPoint", strings.Join(from.EntryPoint, " "), strings.Join(to.EntryPoint, " "))
+	d.addMap("env", from.Environment, to.Environment)
+	d.addMap("secret", from.Secrets, to.Secrets)
+	d.addMap("port", portMap(from.PortMappings), portMap(to.PortMappings))
+	d.add("logDriver", from.LogDriver, to.LogDriver)
+	d.addMap("logOption", from.LogOptions, to.LogOptions)
+}
+
+// DiffTaskDefinitions lists the settings that differ between two task
+// definitions: task level sizing and roles first, then each container by name
+// in the order they appear in to, followed by containers that were removed.
+func DiffTaskDefinitions(from, to TaskDefinition) []TaskDefinitionChange {
+	d := &taskDefDiff{}
+	d.add("cpu", from.CPU, to.CPU)
+	d.add("memory", from.Memory, to.Memory)
+	d.add("networkMode", from.NetworkMode, to.NetworkMode)
+	d.add("taskRole", from.TaskRoleArn, to.TaskRoleArn)
+	d.add("executionRole", from.ExecutionRoleArn, to.ExecutionRoleArn)
+
+	fromContainers := make(map[string]ContainerDefinition, len(from.Containers))
+	for _, c := range from.Containers {
+		fromContainers[c.Name] = c
+	}
+	seen := make(map[string]bool, len(to.Containers))
+	for _, c := range to.Containers {
+		seen[c.Name] = true
+		d.container = c.Name
+		if prev, ok := fromContainers[c.Name]; ok {
+			d.diffContainers(prev, c)
+		} else {
+			d.add("container", "", c.Image)
+		}
+	}
+	for _, c := range from.Containers {
+		if !seen[c.Name] {
+			d.container = c.Name
+			d.add("container", c.Image, "")
+		}
+	}
+	return d.changes
+}
+
+// MaskEnvironment returns copies of the task definitions with their
+// environment values hidden, so that a diff of them only tells which
+// variables were added, removed or changed. Use it before showing a diff
+// somewhere the values should not be posted, such as a chat channel.
+func MaskEnvironment(from, to TaskDefinition) (TaskDefinition, TaskDefinition) {
+	fromEnv := make(map[string]map[string]string, len(from.Containers))
+	for _, c := range from.Containers {
+		fromEnv[c.Name] = c.Environment
+	}
+	toEnv := make(map[string]map[string]string, len(to.Containers))
+	for _, c := range to.Containers {
+		toEnv[c.Name] = c.Environment
+	}
+	mask := func(td TaskDefinition, other map[string]map[string]string, changed string) TaskDefinition {
+		containers := make([]ContainerDefinition, len(td.Containers))
+		for i, c := range td.Containers {
+			env := make(map[string]string, len(c.Environment))
+			for k, v := range c.Environment {
+				if prev, ok := other[c.Name][k]; ok && prev != v {
+					env[k] = changed
+				} else {
+					env[k] = "(hidden)"
+				}
+			}
+			c.Environment = env
+			containers[i] = c
+		}
+		td.Containers = containers
+		return td
+	}
+	return mask(from, toEnv, "(hidden)"), mask(to, fromEnv, "(changed)")
+}
@@ -0,0 +1,141 @@
+package libecs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmaxim/ecstools/libecs"
+)
+
+func TestDiffTaskDefinitions(t *testing.T) {
+	base := func() libecs.TaskDefinition {
+		return libecs.TaskDefinition{
+			Family:   "web",
+			Revision: 12,
+			CPU:      "512",
+			Memory:   "1024",
+			Containers: []libecs.ContainerDefinition{{
+				Name:        "web",
+				Image:       "web:v12",
+				Memory:      1024,
+				Essential:   true,
+				Command:     []string{"serve", "--port", "8080"},
+				Environment: map[string]string{"LOG_LEVEL": "info", "MODE": "prod"},
+				PortMappings: []libecs.PortMapping{
+					{ContainerPort: 8080, Protocol: "tcp"},
+				},
+			}},
+		}
+	}
+	sidecar := libecs.ContainerDefinition{Name: "envoy", Image: "envoy:v1"}
+
+	cases := []struct {
+		name   string
+		change func(td *libecs.TaskDefinition)
+		want   []libecs.TaskDefinitionChange
+	}{
+		{
+			name:   "identical",
+			change: func(td *libecs.TaskDefinition) {},
+		},
+		{
+			name: "task size",
+			change: func(td *libecs.TaskDefinition) {
+				td.CPU = "1024"
+				td.Memory = ""
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Field: "cpu", From: "512", To: "1024"},
+				{Field: "memory", From: "1024"},
+			},
+		},
+		{
+			name: "image and command",
+			change: func(td *libecs.TaskDefinition) {
+				td.Containers[0].Image = "web:v13"
+				td.Containers[0].Command = []string{"serve"}
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Container: "web", Field: "image", From: "web:v12", To: "web:v13"},
+				{Container: "web", Field: "command", From: "serve --port 8080", To: "serve"},
+			},
+		},
+		{
+			name: "environment",
+			change: func(td *libecs.TaskDefinition) {
+				td.Containers[0].Environment = map[string]string{"LOG_LEVEL": "debug", "REGION": "us-east-1"}
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Container: "web", Field: "env[LOG_LEVEL]", From: "info", To: "debug"},
+				{Container: "web", Field: "env[MODE]", From: "prod"},
+				{Container: "web", Field: "env[REGION]", To: "us-east-1"},
+			},
+		},
+		{
+			name: "host port",
+			change: func(td *libecs.TaskDefinition) {
+				td.Containers[0].PortMappings = []libecs.PortMapping{
+					{ContainerPort: 8080, HostPort: 80, Protocol: "tcp"},
+				}
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Container: "web", Field: "port[8080/tcp]", From: "dynamic host port", To: "host port 80"},
+			},
+		},
+		{
+			name: "container added",
+			change: func(td *libecs.TaskDefinition) {
+				td.Containers = append(td.Containers, sidecar)
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Container: "envoy", Field: "container", To: "envoy:v1"},
+			},
+		},
+		{
+			name: "container removed",
+			change: func(td *libecs.TaskDefinition) {
+				td.Containers = nil
+			},
+			want: []libecs.TaskDefinitionChange{
+				{Container: "web", Field: "container", From: "web:v12"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			from, to := base(), base()
+			to.Revision++
+			c.change(&to)
+			if got := libecs.DiffTaskDefinitions(from, to); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("DiffTaskDefinitions() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMaskEnvironment(t *testing.T) {
+	from := libecs.TaskDefinition{Family: "web", Revision: 12, Containers: []libecs.ContainerDefinition{{
+		Name:        "web",
+		Image:       "web:v12",
+		Environment: map[string]string{"API_KEY": "old-secret", "MODE": "prod", "REMOVED": "gone-secret"},
+	}}}
+	to := libecs.TaskDefinition{Family: "web", Revision: 13, Containers: []libecs.ContainerDefinition{{
+		Name:        "web",
+		Image:       "web:v13",
+		Environment: map[string]string{"API_KEY": "new-secret", "MODE": "prod", "ADDED": "added-secret"},
+	}}}
+
+	maskedFrom, maskedTo := libecs.MaskEnvironment(from, to)
+	want := []libecs.TaskDefinitionChange{
+		{Container: "web", Field: "image", From: "web:v12", To: "web:v13"},
+		{Container: "web", Field: "env[ADDED]", To: "(hidden)"},
+		{Container: "web", Field: "env[API_KEY]", From: "(hidden)", To: "(changed)"},
+		{Container: "web", Field: "env[REMOVED]", From: "(hidden)"},
+	}
+	if got := libecs.DiffTaskDefinitions(maskedFrom, maskedTo); !reflect.DeepEqual(got, want) {
+		t.Errorf("masked diff = %+v, want %+v", got, want)
+	}
+	if from.Containers[0].Environment["API_KEY"] != "old-secret" || to.Containers[0].Environment["API_KEY"] != "new-secret" {
+		t.Errorf("MaskEnvironment changed its arguments")
+	}
+}
@@ -0,0 +1,125 @@
+package libecs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// TaskMetricsSource selects where per-task CPU and memory come from.
+type TaskMetricsSource string
+
+const (
+	// TaskMetricsAuto uses Container Insights for Fargate tasks only, since
+	// EC2 tasks are covered by their instance's metrics. It is the default.
+	TaskMetricsAuto TaskMetricsSource = "auto"
+	// TaskMetricsContainerInsights uses Container Insights for every task,
+	// which must be enabled on the cluster.
+	TaskMetricsContainerInsights TaskMetricsSource = "container-insights"
+	// TaskMetricsInstance only reports instance metrics.
+	TaskMetricsInstance TaskMetricsSource = "instance"
+)
+
+func ParseTaskMetricsSource(s string) (TaskMetricsSource, error) {
+	switch source := TaskMetricsSource(strings.ToLower(s)); source {
+	case TaskMetricsAuto, TaskMetricsContainerInsights, TaskMetricsInstance:
+		return source, nil
+	}
+	return "", fmt.Errorf("unknown task metrics source: %s", s)
+}
+
+// wantTaskMetrics reports whether the task's own metrics should be looked up
+// in Container Insights.
+func (e *ECS) wantTaskMetrics(t *Task) bool {
+	if t.Status == ecs.DesiredStatusStopped {
+		return false
+	}
+	switch e.config.TaskMetrics {
+	case TaskMetricsContainerInsights:
+		return true
+	case TaskMetricsInstance:
+		return false
+	default:
+		return t.LaunchType == ecs.LaunchTypeFargate
+	}
+}
+
+// containerInsightsNamespace holds the per-task metrics published when
+// Container Insights is enabled on the cluster.
+const containerInsightsNamespace = "ECS/ContainerInsights"
+
+// TaskMetrics is a task's own resource usage as reported by Container
+// Insights. CPU is in CPU units and memory in MiB.
+type TaskMetrics struct {
+	CPUUtilized    float64
+	CPUReserved    float64
+	MemoryUtilized float64
+	MemoryReserved float64
+}
+
+func percent(used, reserved float64) float64 {
+	if reserved <= 0 {
+		return 0
+	}
+	return 100 * used / reserved
+}
+
+// CPUPercent is the task's CPU usage as a percentage of its reservation.
+func (m TaskMetrics) CPUPercent() float64 {
+	return percent(m.CPUUtilized, m.CPUReserved)
+}
+
+// MemoryPercent is the task's memory usage as a percentage of its
+// reservation.
+func (m TaskMetrics) MemoryPercent() float64 {
+	return percent(m.MemoryUtilized, m.MemoryReserved)
+}
+
+// fillTaskMetrics attaches Container Insights usage to the tasks selected by
+// ECSConfig.TaskMetrics. Tasks without data, because they have stopped or
+// Container Insights is off, are left without TaskMetrics.
+func (e *ECS) fillTaskMetrics(ctx context.Context, tasks []*Task) error {
+	var queries []*cloudwatch.MetricDataQuery
+	var found []*Task
+	for _, t := range tasks {
+		if !e.wantTaskMetrics(t) {
+			continue
+		}
+		dims := map[string]string{
+			"ClusterName": e.cluster(),
+			"TaskId":      shortTaskID(t.Arn),
+		}
+		i := len(found)
+		queries = append(queries,
+			metricStatQuery(fmt.Sprintf("cpu%d", i), containerInsightsNamespace, "CpuUtilized", dims, "Average", 60),
+			metricStatQuery(fmt.Sprintf("cpures%d", i), containerInsightsNamespace, "CpuReserved", dims, "Average", 60),
+			metricStatQuery(fmt.Sprintf("mem%d", i), containerInsightsNamespace, "MemoryUtilized", dims, "Average", 60),
+			metricStatQuery(fmt.Sprintf("memres%d", i), containerInsightsNamespace, "MemoryReserved", dims,
+				"Average", 60))
+		found = append(found, t)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	values, err := e.latestMetricValues(ctx, queries)
+	if err != nil {
+		return err
+	}
+	for i, t := range found {
+		m := TaskMetrics{
+			CPUUtilized:    values[4*i],
+			CPUReserved:    values[4*i+1],
+			MemoryUtilized: values[4*i+2],
+			MemoryReserved: values[4*i+3],
+		}
+		if m.CPUReserved == 0 && m.MemoryReserved == 0 {
+			continue
+		}
+		t.TaskMetrics = &m
+	}
+	return nil
+}